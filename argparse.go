@@ -1,8 +1,12 @@
 package argparse
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"strconv"
@@ -10,28 +14,115 @@ import (
 	"unicode"
 )
 
-var (
-	BreakLineThreshold = 80
-)
+// returned by Parse when help is requested and Exit returns
+var ErrHelp = errors.New("help requested")
+
+// wrapping width of parsers without Width when $COLUMNS is not set
+var BreakLineThreshold = 80
 
 type ArgParser struct {
 	Name        string
 	Description string
-
-	ctx *Context
+	// shown after the commands in the usage, see AddExample
+	Examples []Example
+	// text shown at the end of the usage
+	Epilog string
+	// wrapping width of the usage. defaults to the Width of the parent,
+	// $COLUMNS or BreakLineThreshold
+	Width int
+	// metavar of options without one. defaults to the Metavar of the
+	// parent or "var"
+	Metavar string
+	// colors of the usage and errors. default to the ones of the parent
+	Theme *Theme
+	Color ColorMode
+	// destination of help, errors and completion scripts, and source of
+	// prompts. default to the ones of the parent, os.Stdin, os.Stdout and
+	// os.Stderr
+	Stdin  io.Reader
+	Stdout io.Writer
+	Stderr io.Writer
+	// prompt for required options that were not given, reading from Stdin
+	// and writing to Stderr, instead of failing. applies to subparsers too
+	PromptRequired bool
+	// shown by the option added with AddVersionOption. see SetBuildInfo
+	Version   string
+	BuildInfo *BuildInfo
+
+	// called after help or version is shown. defaults to the one of the parent or
+	// os.Exit. if it returns, parsing is aborted with ErrHelp or ErrVersion
+	Exit func(code int)
+
+	// prompt of Repl. defaults to the program name followed by "> "
+	Prompt string
+
+	// text/template used by Usage, executed with a *Help. subparsers
+	// without one use the template of their parent. see DefaultHelpTemplate
+	HelpTemplate string
 
 	opts map[string]*Option
 	// positionals
 	pos []*Option
+	// option groups in the order they were added
+	groups []*OptionGroup
 
 	subparsers     map[string]*ArgParser
 	subparsercount int
-
-	// selected subparser
-	SubParser     *ArgParser
-	SubParserName string
+	parent         *ArgParser
+	// name, aliases and position this parser was added with
+	subname    string
+	subaliases []string
+	subindex   int
+
+	// dont list this parser in the commands of its parent
+	Hidden bool
+
+	// fail if no subparser is selected
+	SubParserRequired bool
+	// subparser selected when none is given. arguments not recognized by
+	// this parser are parsed by it
+	DefaultSubParser string
+
+	// dont parse arguments, they are available through Invocation.Args
+	RawArgs bool
+
+	// resolve unknown commands to executables named PluginPrefix+command
+	Plugins bool
+	// defaults to the parser name with spaces replaced by "-", followed by "-"
+	PluginPrefix string
+	// directories searched for plugins. defaults to $PATH
+	PluginDirs []string
+
+	// replace arguments starting with ResponseFilePrefix by the arguments
+	// read from the file named after it. applies to subparsers too.
+	// lines are split like a shell does, see Split, so arguments with
+	// blanks, quotes or backslashes must be quoted, e.g. 'C:\Program Files'.
+	// a word starting with # comments out the rest of the line
+	ResponseFiles bool
+	// defaults to "@"
+	ResponseFilePrefix string
+	// read response files as one argument per line, taken as is. empty
+	// lines are skipped
+	ResponseFileLines bool
+
+	// make every option of this parser persistent
+	PersistentOptions bool
+
+	// dont add -h, --help to subparsers of a parser that has it
+	DisableHelpPropagation bool
+	// whether -h, --help was added by NewWithDefaults or propagated
+	help bool
+
+	// called by Execute when this is the selected (innermost) parser
+	Run func(ctx context.Context, inv *Invocation) error
+	// called by Execute on every selected parser from the root down, before Run
+	PreRun func(ctx context.Context, inv *Invocation) error
+	// called by Execute on every selected parser from the innermost up, after Run
+	PostRun func(ctx context.Context, inv *Invocation) error
 
 	unparceable func(*Context, string, error)
+	// called after a successful parse that selected this parser last
+	parsed func(*Context)
 
 	optcounter int
 }
@@ -46,14 +137,63 @@ func New() *ArgParser {
 func NewWithDefaults() *ArgParser {
 	a := New()
 	a.Name = os.Args[0]
-	a.AddOptionWithAlias(Option{Name: "h", Description: "shows usage and exits", Callback: func(ctx *Context, args ...string) {
-		fmt.Print(a.Usage())
-		os.Exit(0)
-	}}, "help")
+	a.addHelpOption()
 	return a
 }
 
+// return the metavar of opt: its own, the name of a positional or the
+// Metavar of a or its closest parent that has one
+func (a *ArgParser) metavar(opt *Option) string {
+	if len(opt.Metavar) > 0 {
+		return opt.Metavar
+	}
+	if opt.Positional {
+		return opt.Name
+	}
+	for p := a; p != nil; p = p.parent {
+		if len(p.Metavar) > 0 {
+			return p.Metavar
+		}
+	}
+	return "var"
+}
+
+func (a *ArgParser) stdout() io.Writer {
+	for p := a; p != nil; p = p.parent {
+		if p.Stdout != nil {
+			return p.Stdout
+		}
+	}
+	return os.Stdout
+}
+
+func (a *ArgParser) stderr() io.Writer {
+	for p := a; p != nil; p = p.parent {
+		if p.Stderr != nil {
+			return p.Stderr
+		}
+	}
+	return os.Stderr
+}
+
+func (a *ArgParser) exit(code int) {
+	for p := a; p != nil; p = p.parent {
+		if p.Exit != nil {
+			p.Exit(code)
+			return
+		}
+	}
+	os.Exit(code)
+}
+
 func (a *ArgParser) AddOption(opt Option) {
+	a.addOption(opt)
+	if opt.Secret && opt.SecretFile {
+		a.addSecretFileOption(opt)
+	}
+}
+
+func (a *ArgParser) addOption(opt Option) {
 	opt.sort = a.optcounter
 	a.optcounter++
 	if len(opt.Name) == 0 {
@@ -63,9 +203,15 @@ func (a *ArgParser) AddOption(opt Option) {
 		panic("option name cant start with -")
 	}
 	if opt.Positional {
+		if opt.Persistent {
+			panic("positional cant be persistent")
+		}
 		if opt.Nargs == 0 {
 			panic("cant have positional with nargs == 0")
 		}
+		if len(opt.Replacement) > 0 {
+			panic("positional cant have replacement")
+		}
 		if len(a.pos) > 0 && a.pos[len(a.pos)-1].Nargs == -1 {
 			panic("positional with nargs -1 must be the last")
 		}
@@ -74,39 +220,95 @@ func (a *ArgParser) AddOption(opt Option) {
 		if opt.Nargs < 0 {
 			panic("cant have option with nargs < 0")
 		}
+		if len(opt.Replacement) > 0 {
+			a.checkReplacement(&opt)
+		}
+		// -h, --help may have been propagated by the parent. options
+		// named after them must be added before a is made a subparser
+		if a.help && (opt.Name == "h" || opt.Name == "help") {
+			panic("option name conflicts with -h, --help")
+		}
 		a.opts[opt.Name] = &opt
 	}
 }
 
+func (a *ArgParser) checkReplacement(opt *Option) {
+	replacement, ok := a.opts[opt.Replacement]
+	if !ok {
+		panic("unknown replacement option")
+	}
+	if replacement.Nargs != opt.Nargs {
+		panic("replacement option must have the same nargs")
+	}
+}
+
 func (a *ArgParser) AddOptionWithAlias(opt Option, aliases ...string) {
 	if opt.Positional {
 		panic("positional cant have alias")
 	}
-	a.AddOption(opt)
+	a.addOption(opt)
 	opt.basealias = opt.Name
 	for _, alias := range aliases {
 		tmp := opt
 		tmp.Name = alias
-		a.AddOption(tmp)
+		a.addOption(tmp)
+	}
+	if opt.Secret && opt.SecretFile {
+		a.addSecretFileOption(opt)
 	}
 }
 
+// safe for concurrent use
 func (a *ArgParser) Parse(args ...string) error {
-	a.ctx = &Context{args: args, parser: a}
-	err := a.ctx.parse()
+	_, err := a.ParseInvocation(args...)
+	return err
+}
+
+// same as Parse but returns the per-parse state, including the selected
+// subparsers and the arguments consumed by each option
+func (a *ArgParser) ParseInvocation(args ...string) (*Invocation, error) {
+	inv := newInvocation(a, nil)
+	return inv, a.parse(inv, args...)
+}
+
+func (a *ArgParser) parse(inv *Invocation, args ...string) error {
+	if a.RawArgs {
+		inv.Args = append(inv.Args, args...)
+		return nil
+	}
+
+	ctx := &Context{args: args, parser: a, inv: inv}
+	err := ctx.parse()
 	if err != nil {
 		return err
 	}
 
-	required := make([]string, 0)
-	for _, opt := range a.opts {
-		if opt.Required && !opt.set && len(opt.basealias) == 0 {
-			required = append(required, opt.String())
+	if inv.completion != nil {
+		if inv.Sub == nil {
+			inv.completion.pindex = ctx.pindex
 		}
+		return nil
 	}
 
-	for _, opt := range a.pos {
-		if opt.Required && !opt.set {
+	if inv.Sub == nil && !ctx.abort {
+		if sub := a.defaultSubParser(); sub != nil {
+			if err := ctx.selectSubParser(sub); err != nil {
+				return err
+			}
+		} else if a.SubParserRequired {
+			return fmt.Errorf("a command is required: %s", strings.Join(a.subParserNames(false), ", "))
+		}
+	}
+
+	if a.promptRequired() {
+		if err := ctx.promptMissing(); err != nil {
+			return err
+		}
+	}
+
+	required := make([]string, 0)
+	for _, opt := range a.Options() {
+		if opt.Required && !inv.set[opt] && len(opt.basealias) == 0 {
 			required = append(required, opt.String())
 		}
 	}
@@ -118,22 +320,143 @@ func (a *ArgParser) Parse(args ...string) error {
 		return fmt.Errorf("the following options are required: %s", strings.Join(required, ", "))
 	}
 
+	if a.parsed != nil && inv.Sub == nil && !ctx.abort {
+		a.parsed(ctx)
+		return ctx.err
+	}
+
 	return nil
 }
 
+// parses s split like a shell does. see Split
+func (a *ArgParser) ParseString(s string) error {
+	_, err := a.ParseStringInvocation(s)
+	return err
+}
+
+func (a *ArgParser) ParseStringInvocation(s string) (*Invocation, error) {
+	args, err := Split(s)
+	if err != nil {
+		return nil, err
+	}
+	return a.ParseInvocation(args...)
+}
+
 func (a *ArgParser) ParseArgs() error {
 	return a.Parse(os.Args[1:]...)
 }
 
+func (a *ArgParser) ParseArgsInvocation() (*Invocation, error) {
+	return a.ParseInvocation(os.Args[1:]...)
+}
+
+// return option by name, also searching persistent options of parent
+// parsers. the parser that declared the option is returned along with it
+func (a *ArgParser) findOption(name string) (*Option, *ArgParser) {
+	if opt, ok := a.opts[name]; ok {
+		return opt, a
+	}
+	for p := a.parent; p != nil; p = p.parent {
+		if opt, ok := p.opts[name]; ok && p.persistent(opt) {
+			return opt, p
+		}
+	}
+	return nil, nil
+}
+
+func (a *ArgParser) persistent(opt *Option) bool {
+	opt = a.base(opt)
+	return a.PersistentOptions || opt.Persistent || (opt.group != nil && opt.group.Persistent)
+}
+
+// return persistent options inherited from parent parsers
+func (a *ArgParser) globalAliases() [][]*Option {
+	aliases := make([][]*Option, 0)
+	for p := a.parent; p != nil; p = p.parent {
+		for _, alias := range p.Aliases() {
+			if alias[0].Positional || !p.persistent(alias[0]) {
+				continue
+			}
+			shadowed := false
+			for _, opt := range alias {
+				if tmp, owner := a.findOption(opt.Name); tmp != nil && owner != p {
+					shadowed = true
+					break
+				}
+			}
+			if !shadowed {
+				aliases = append(aliases, alias)
+			}
+		}
+	}
+	return aliases
+}
+
+// return option (or positional) by name
+func (a *ArgParser) lookup(name string) *Option {
+	if opt, ok := a.opts[name]; ok {
+		return a.base(opt)
+	}
+	for _, opt := range a.pos {
+		if opt.Name == name {
+			return opt
+		}
+	}
+	return nil
+}
+
+// return the option an alias refers to
+func (a *ArgParser) base(opt *Option) *Option {
+	if len(opt.basealias) == 0 {
+		return opt
+	}
+	if base, ok := a.opts[opt.basealias]; ok {
+		return base
+	}
+	return opt
+}
+
 func (a *ArgParser) Unparceable(callback func(*Context, string, error)) {
 	a.unparceable = callback
 }
 
 func (a *ArgParser) AddSubParser(name string, p *ArgParser) {
-	p.Name = a.Name + " " + name
-	p.subparsercount = a.subparsercount
+	a.AddSubParserWithAlias(name, p)
+}
+
+func (a *ArgParser) AddSubParserWithAlias(name string, p *ArgParser, aliases ...string) {
+	p.parent = a
+	p.subname = name
+	p.subaliases = aliases
+	p.subindex = a.subparsercount
 	a.subparsercount += 1
 	a.subparsers[name] = p
+	for _, alias := range aliases {
+		a.subparsers[alias] = p
+	}
+	p.rename()
+	if a.help && !a.DisableHelpPropagation {
+		p.propagateHelp()
+	}
+}
+
+// sets the name of a and its subparsers after the name of their parent
+func (a *ArgParser) rename() {
+	a.Name = a.parent.Name + " " + a.subname
+	for _, name := range a.subParserNames(true) {
+		a.subparsers[name].rename()
+	}
+}
+
+// return the program name followed by the subparser names leading to a.
+// the program name is the base name of os.Args[0] if a has no Name
+func (a *ArgParser) commandPath() []string {
+	fields := strings.Fields(a.Name)
+	if len(fields) == 0 {
+		return []string{filepath.Base(os.Args[0])}
+	}
+	fields[0] = filepath.Base(fields[0])
+	return fields
 }
 
 func (a *ArgParser) LoadStruct(s any) {
@@ -141,10 +464,25 @@ func (a *ArgParser) LoadStruct(s any) {
 	v := p.Elem()
 	t := v.Type()
 
+	// replacements may name options of later fields, so they are set once
+	// every option is added
+	replacements := make([][2]string, 0)
+
 	for i := 0; i < v.NumField(); i++ {
 		fv := v.Field(i)
 		ft := t.Field(i)
 
+		// blank fields describe the parser
+		if ft.Name == "_" {
+			if tmp, ok := ft.Tag.Lookup("epilog"); ok {
+				a.Epilog = tmp
+			}
+			if tmp, ok := ft.Tag.Lookup("example"); ok {
+				a.AddExample(tmp, ft.Tag.Get("explanation"))
+			}
+			continue
+		}
+
 		if !ft.IsExported() {
 			continue
 		}
@@ -185,77 +523,118 @@ func (a *ArgParser) LoadStruct(s any) {
 		description, _ := ft.Tag.Lookup("description")
 		metavar, _ := ft.Tag.Lookup("metavar")
 
+		choices := make([]string, 0)
+		if tmp, ok := ft.Tag.Lookup("choices"); ok {
+			choices = append(choices, strings.Split(tmp, ",")...)
+		}
+
+		def, _ := ft.Tag.Lookup("default")
+		env, _ := ft.Tag.Lookup("env")
+
+		var secret bool
+		if tmp, ok := ft.Tag.Lookup("secret"); ok {
+			if s, err := strconv.ParseBool(tmp); err != nil {
+				panic(err)
+			} else {
+				secret = s
+			}
+		}
+
+		var hidden bool
+		if tmp, ok := ft.Tag.Lookup("hidden"); ok {
+			if h, err := strconv.ParseBool(tmp); err != nil {
+				panic(err)
+			} else {
+				hidden = h
+			}
+		}
+		deprecated, _ := ft.Tag.Lookup("deprecated")
+		if tmp, ok := ft.Tag.Lookup("replacement"); ok {
+			replacements = append(replacements, [2]string{name, tmp})
+		}
+
+		var group *OptionGroup
+		if tmp, ok := ft.Tag.Lookup("group"); ok {
+			group = a.AddGroup(tmp, "")
+		}
+
+		setup := func(opt Option) Option {
+			opt.group = group
+			return opt.SetAll(required, description, metavar).SetChoices(choices...).SetDefault(def).SetEnv(env).
+				SetHidden(hidden).SetDeprecated(deprecated).SetSecret(secret)
+		}
+
 		switch fv.Interface().(type) {
 		case string:
 			switch opttype {
 			case "":
-				a.AddOptionWithAlias(String(name, fv.Addr().Interface().(*string)).SetAll(required, description, metavar), aliases...)
+				a.AddOptionWithAlias(setup(String(name, fv.Addr().Interface().(*string))), aliases...)
 			case "positional":
-				a.AddOption(StringPositional(name, fv.Addr().Interface().(*string)).SetAll(required, description, metavar))
+				a.AddOption(setup(StringPositional(name, fv.Addr().Interface().(*string))))
 			default:
 				panic("unsupported type")
 			}
 		case *string:
 			switch opttype {
 			case "":
-				a.AddOptionWithAlias(StringAddr(name, fv.Addr().Interface().(**string)).SetAll(required, description, metavar), aliases...)
+				a.AddOptionWithAlias(setup(StringAddr(name, fv.Addr().Interface().(**string))), aliases...)
 			case "positional":
-				a.AddOption(StringAddrPositional(name, fv.Addr().Interface().(**string)).SetAll(required, description, metavar))
+				a.AddOption(setup(StringAddrPositional(name, fv.Addr().Interface().(**string))))
 			default:
 				panic("unsupported type")
 			}
 		case []string:
 			switch opttype {
 			case "":
-				a.AddOptionWithAlias(StringAppend(name, fv.Addr().Interface().(*[]string)).SetAll(required, description, metavar), aliases...)
+				a.AddOptionWithAlias(setup(StringAppend(name, fv.Addr().Interface().(*[]string))), aliases...)
 			case "positional":
-				a.AddOption(StringAppendPositional(name, fv.Addr().Interface().(*[]string)).SetAll(required, description, metavar))
+				a.AddOption(setup(StringAppendPositional(name, fv.Addr().Interface().(*[]string))))
 			case "rest-positional":
-				a.AddOption(StringRestPositional(name, fv.Addr().Interface().(*[]string)).SetAll(required, description, metavar))
+				a.AddOption(setup(StringRestPositional(name, fv.Addr().Interface().(*[]string))))
 			default:
 				panic("unsupported type")
 			}
 		case bool:
 			switch opttype {
 			case "":
-				a.AddOptionWithAlias(Bool(name, fv.Addr().Interface().(*bool)).SetAll(required, description, metavar), aliases...)
+				a.AddOptionWithAlias(setup(Bool(name, fv.Addr().Interface().(*bool))), aliases...)
 			default:
 				panic("unsupported type")
 			}
 		case int:
 			switch opttype {
 			case "":
-				a.AddOptionWithAlias(Int(name, fv.Addr().Interface().(*int)).SetAll(required, description, metavar), aliases...)
+				a.AddOptionWithAlias(setup(Int(name, fv.Addr().Interface().(*int))), aliases...)
 			case "positional":
-				a.AddOption(IntPositional(name, fv.Addr().Interface().(*int)).SetAll(required, description, metavar))
+				a.AddOption(setup(IntPositional(name, fv.Addr().Interface().(*int))))
 			default:
 				panic("unsupported type")
 			}
 		case []int:
 			switch opttype {
 			case "positional":
-				a.AddOption(IntAppendPositional(name, fv.Addr().Interface().(*[]int)).SetAll(required, description, metavar))
+				a.AddOption(setup(IntAppendPositional(name, fv.Addr().Interface().(*[]int))))
 			default:
 				panic("unsupported type")
 			}
 		case uint:
 			switch opttype {
 			case "":
-				a.AddOptionWithAlias(Uint(name, fv.Addr().Interface().(*uint)).SetAll(required, description, metavar), aliases...)
+				a.AddOptionWithAlias(setup(Uint(name, fv.Addr().Interface().(*uint))), aliases...)
 			default:
 				panic("unsupported type")
 			}
 		case func():
 			switch opttype {
 			case "":
-				a.AddOptionWithAlias(Func(name, fv.Interface().(func())).SetAll(required, description, metavar), aliases...)
+				a.AddOptionWithAlias(setup(Func(name, fv.Interface().(func()))), aliases...)
 			default:
 				panic("unsupported type")
 			}
 		case ArgParser:
-			a.AddSubParser(name, fv.Addr().Interface().(*ArgParser))
+			a.AddSubParserWithAlias(name, fv.Addr().Interface().(*ArgParser), aliases...)
 		case *ArgParser:
-			a.AddSubParser(name, fv.Interface().(*ArgParser))
+			a.AddSubParserWithAlias(name, fv.Interface().(*ArgParser), aliases...)
 		default:
 			if ft.Type.Kind() == reflect.Pointer {
 				fv = fv.Elem()
@@ -266,7 +645,7 @@ func (a *ArgParser) LoadStruct(s any) {
 				case "subparser":
 					sub := FromStruct(fv.Addr().Interface())
 					sub.Description = description
-					a.AddSubParser(name, sub)
+					a.AddSubParserWithAlias(name, sub, aliases...)
 				default:
 					panic("unsupported type")
 				}
@@ -275,6 +654,18 @@ func (a *ArgParser) LoadStruct(s any) {
 			}
 		}
 	}
+
+	for _, r := range replacements {
+		if _, ok := a.opts[r[0]]; !ok {
+			panic("positional cant have replacement")
+		}
+		for _, opt := range a.opts {
+			if opt.Name == r[0] || opt.basealias == r[0] {
+				opt.Replacement = r[1]
+				a.checkReplacement(opt)
+			}
+		}
+	}
 }
 
 func FromStruct(s any) *ArgParser {
@@ -323,112 +714,65 @@ func (a *ArgParser) Aliases() [][]*Option {
 	return aliases
 }
 
-func (a *ArgParser) String() string {
+// return the arguments shown in the usage line
+func (a *ArgParser) usageStrings() []string {
 	opts := a.Options()
 
 	strs := make([]string, 0)
 	for _, opt := range opts {
-		if len(opt.basealias) != 0 {
+		if len(opt.basealias) != 0 || opt.Hidden {
 			continue
 		}
-		strs = append(strs, opt.string())
+		strs = append(strs, opt.usage(a.metavar(opt)))
 	}
 
-	if len(a.subparsers) > 0 {
+	if len(a.subParserNames(false)) > 0 || len(a.pluginNames()) > 0 {
 		strs = append(strs, "<command>")
 	}
+
+	return strs
+}
+
+func (a *ArgParser) String() string {
+	strs := a.usageStrings()
 	str := fmt.Sprintf("usage: %s ", a.Name)
 
 	if len(strs) == 0 {
 		return str[:len(str)-1] + "\n"
 	}
 
-	return formatString(str, len(str), BreakLineThreshold, false, strs...)
+	return formatString(str, displayWidth(str), a.width(), false, strs...)
 }
 
-func (a *ArgParser) Usage() string {
-	b := &strings.Builder{}
-	b.WriteString(a.String())
-	if len(a.Description) > 0 {
-		b.WriteRune('\n')
-		b.WriteString(formatString("", 0, BreakLineThreshold, false, strings.FieldsFunc(a.Description, unicode.IsSpace)...))
-	}
-
-	strs := make([]string, 0)
-	aliases := a.Aliases()
-	for _, alias := range aliases {
-		tmp := make([]string, 0)
-		for i, opt := range alias {
-			if i == len(alias)-1 && opt.Nargs > 0 && !opt.Positional {
-				metavar := opt.Metavar
-				if len(metavar) == 0 {
-					metavar = "var"
-				}
-				tmp = append(tmp, fmt.Sprintf("%s %s", opt.String(), metavar))
-			} else {
-				tmp = append(tmp, opt.String())
-			}
-		}
-		strs = append(strs, "    "+strings.Join(tmp, ", "))
-	}
-
-	max := 0
-	for _, s := range strs {
-		if len(s) > max {
-			max = len(s)
-		}
-	}
-	max += 5
-
-	if len(strs) > 0 {
-		b.WriteString("\noptions:\n")
-	}
-
-	for i, s := range strs {
-		if len(aliases[i][0].Description) > 0 {
-			pad := max - len(s)
-			for i := 0; i < pad; i++ {
-				s += " "
-			}
-			b.WriteString(formatString(s, len(s), BreakLineThreshold, false, strings.FieldsFunc(aliases[i][0].Description, unicode.IsSpace)...))
-		} else {
-			b.WriteString(s)
-			b.WriteRune('\n')
-		}
+func (a *ArgParser) defaultSubParser() *ArgParser {
+	if len(a.DefaultSubParser) == 0 {
+		return nil
 	}
-
-	if len(a.subparsers) > 0 {
-		b.WriteString("\ncommands:\n")
+	sub, ok := a.subparsers[a.DefaultSubParser]
+	if !ok {
+		panic("unknown default subparser")
 	}
+	return sub
+}
 
+// return canonical subparser names in the order they were added
+func (a *ArgParser) subParserNames(hidden bool) []string {
 	subparsers := make([]string, 0, len(a.subparsers))
 
-	for name := range a.subparsers {
+	for name, sub := range a.subparsers {
+		if name != sub.subname || (sub.Hidden && !hidden) {
+			continue
+		}
 		subparsers = append(subparsers, name)
 	}
 
 	sort.Slice(subparsers, func(i, j int) bool {
 		is := a.subparsers[subparsers[i]]
 		js := a.subparsers[subparsers[j]]
-		return is.subparsercount < js.subparsercount
+		return is.subindex < js.subindex
 	})
 
-	for _, subname := range subparsers {
-		sub := a.subparsers[subname]
-		str := "    " + subname
-		if len(sub.Description) > 0 {
-			pad := max - len(str)
-			for i := 0; i < pad; i++ {
-				str += " "
-			}
-			b.WriteString(formatString(str, len(str), BreakLineThreshold, false, strings.FieldsFunc(sub.Description, unicode.IsSpace)...))
-		} else {
-			b.WriteString(str)
-			b.WriteRune('\n')
-		}
-	}
-
-	return b.String()
+	return subparsers
 }
 
 func camelCaseToDashed(a string) string {
@@ -444,30 +788,37 @@ func camelCaseToDashed(a string) string {
 	return r.String()
 }
 
+// return ss joined by spaces after linestart, breaking lines before a word
+// that would make them wider than breakline. lines after the first (or
+// every line if pad is set) are padded to padlen
 func formatString(linestart string, padlen, breakline int, pad bool, ss ...string) string {
 	line := linestart
+	words := 0
 	b := &strings.Builder{}
 	for _, s := range ss {
-		line += s + " "
+		width := displayWidth(line + s)
+		if pad {
+			width += padlen
+		}
 
-		if (pad && len(line)+padlen > breakline) || (!pad && len(line) > breakline) {
+		if words > 0 && width > breakline {
 			if pad {
-				for i := 0; i < padlen; i++ {
-					b.WriteRune(' ')
-				}
+				b.WriteString(strings.Repeat(" ", padlen))
 			}
 			b.WriteString(strings.TrimRightFunc(line, unicode.IsSpace))
 			b.WriteRune('\n')
 			pad = true
 			line = ""
+			words = 0
 		}
+
+		line += s + " "
+		words++
 	}
 
 	if len(line) > 0 {
 		if pad {
-			for i := 0; i < padlen; i++ {
-				b.WriteRune(' ')
-			}
+			b.WriteString(strings.Repeat(" ", padlen))
 		}
 		b.WriteString(line)
 		b.WriteRune('\n')
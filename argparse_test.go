@@ -1,6 +1,7 @@
 package argparse
 
 import (
+	"strings"
 	"testing"
 )
 
@@ -156,9 +157,11 @@ func TestSubParser(t *testing.T) {
 		edited++
 	}})
 
-	assertError(t, false, parser.Parse("-a", "sub", "-b", "-a"))
+	inv, err := parser.ParseInvocation("-a", "sub", "-b", "-a")
+	assertError(t, false, err)
 	assertEqual(t, edited, 2)
-	assertEqual(t, parser.SubParser, sparser)
+	assertEqual(t, inv.SubParser, sparser)
+	assertEqual(t, inv.SubParserName, "sub")
 
 	sparser.AddSubParser("subsub", ssparser)
 
@@ -170,10 +173,12 @@ func TestSubParser(t *testing.T) {
 		edited--
 	}})
 
-	assertError(t, false, parser.Parse("-a", "sub", "-b", "-a", "subsub", "-a", "-b"))
+	inv, err = parser.ParseInvocation("-a", "sub", "-b", "-a", "subsub", "-a", "-b")
+	assertError(t, false, err)
 	assertEqual(t, edited, 0)
-	assertEqual(t, parser.SubParser, sparser)
-	assertEqual(t, sparser.SubParser, ssparser)
+	assertEqual(t, inv.SubParser, sparser)
+	assertEqual(t, inv.Sub.SubParser, ssparser)
+	assertEqual(t, inv.Leaf().Parser, ssparser)
 }
 
 func TestPositional(t *testing.T) {
@@ -214,3 +219,259 @@ func TestExpand(t *testing.T) {
 	assertSliceEqual(t, []string{"--foo", "bar"}, ctx.expand("--foo=bar"))
 	assertSliceEqual(t, []string{"--foo", ""}, ctx.expand("--foo="))
 }
+
+func TestPersistent(t *testing.T) {
+	parser := New()
+	parser.Width = 80
+	parser.Name = "tool"
+	sparser := New()
+	ssparser := New()
+
+	verbose := 0
+	parser.AddOptionWithAlias(Option{Name: "v", Persistent: true, Description: "verbose", Callback: func(ctx *Context, args ...string) {
+		verbose++
+	}}, "verbose")
+	parser.AddOption(Option{Name: "local", Nargs: 1})
+	parser.AddOption(Option{Name: "config", Nargs: 1, Required: true}.SetPersistent(true))
+
+	parser.AddSubParser("sub", sparser)
+	sparser.AddSubParser("subsub", ssparser)
+	sparser.AddOption(Option{Name: "config", Nargs: 1, Description: "overrides --config"})
+
+	inv, err := parser.ParseInvocation("--config", "a", "sub", "-v", "subsub", "--verbose")
+	assertError(t, false, err)
+	assertEqual(t, 2, verbose)
+	assertEqual(t, true, inv.IsSet("v"))
+	assertEqual(t, true, inv.Leaf().IsSet("verbose"))
+	assertEqual(t, "a", inv.Leaf().Value("config"))
+
+	// sub shadows --config, so the root one is still missing
+	_, err = parser.ParseInvocation("sub", "--config", "b")
+	assertError(t, true, err)
+
+	_, err = parser.ParseInvocation("--config", "a", "sub", "--local", "b")
+	assertError(t, true, err)
+
+	assertEqual(t, "usage: tool sub [--config var] <command> \n\n"+
+		"options:\n"+
+		"    --config var      overrides --config \n\n"+
+		"global options:\n"+
+		"    -v, --verbose     verbose \n\n"+
+		"commands:\n"+
+		"    subsub\n", sparser.Usage())
+
+	assertEqual(t, "usage: tool sub subsub\n\n"+
+		"global options:\n"+
+		"    -v, --verbose     verbose \n"+
+		"    --config var\n", ssparser.Usage())
+
+	parser.PersistentOptions = true
+	assertError(t, false, parser.Parse("--config", "a", "sub", "subsub", "--local", "b"))
+}
+
+func TestSubParserAlias(t *testing.T) {
+	parser := New()
+	parser.Width = 80
+	parser.Name = "tool"
+	remove := New()
+	remove.Description = "removes a file"
+	debug := New()
+	debug.Hidden = true
+
+	parser.AddSubParser("add", New())
+	parser.AddSubParserWithAlias("remove", remove, "rm", "del")
+	parser.AddSubParser("debug", debug)
+
+	for _, name := range []string{"remove", "rm", "del"} {
+		inv, err := parser.ParseInvocation(name)
+		assertError(t, false, err)
+		assertEqual(t, remove, inv.SubParser)
+		assertEqual(t, "remove", inv.SubParserName)
+	}
+
+	inv, err := parser.ParseInvocation("debug")
+	assertError(t, false, err)
+	assertEqual(t, debug, inv.SubParser)
+
+	assertEqual(t, "usage: tool <command> \n\n"+
+		"commands:\n"+
+		"    add\n"+
+		"    remove (rm, del)     removes a file \n", parser.Usage())
+}
+
+func TestRequiredSubParser(t *testing.T) {
+	parser := New()
+	add := New()
+	del := New()
+	parser.AddOption(Option{Name: "v"})
+	parser.AddSubParser("add", add)
+	parser.AddSubParser("del", del)
+
+	assertError(t, false, parser.Parse("-v"))
+
+	parser.SubParserRequired = true
+	err := parser.Parse("-v")
+	assertError(t, true, err)
+	assertEqual(t, "a command is required: add, del", err.Error())
+
+	file := ""
+	add.AddOption(Option{Name: "f"})
+	add.AddOption(StringPositional("file", &file))
+	parser.DefaultSubParser = "add"
+
+	inv, err := parser.ParseInvocation("-v")
+	assertError(t, false, err)
+	assertEqual(t, add, inv.SubParser)
+	assertEqual(t, "add", inv.SubParserName)
+
+	inv, err = parser.ParseInvocation("-v", "-f", "foo")
+	assertError(t, false, err)
+	assertEqual(t, add, inv.SubParser)
+	assertEqual(t, true, inv.Sub.IsSet("f"))
+	assertEqual(t, "foo", file)
+
+	inv, err = parser.ParseInvocation("del")
+	assertError(t, false, err)
+	assertEqual(t, del, inv.SubParser)
+
+	assertError(t, true, parser.Parse("-x"))
+}
+
+func TestChoices(t *testing.T) {
+	parser := New()
+	color := ""
+	parser.AddOption(String("color", &color).SetChoices("always", "never"))
+
+	assertError(t, false, parser.Parse("--color", "never"))
+	assertEqual(t, "never", color)
+
+	err := parser.Parse("--color", "auto")
+	assertError(t, true, err)
+	assertEqual(t, `option --color "auto" is invalid, choose from: always, never`, err.Error())
+	assertEqual(t, "never", color)
+}
+
+func TestDefaultEnv(t *testing.T) {
+	type S struct {
+		Level string `default:"info" choices:"debug,info"`
+		Depth int    `default:"1" env:"TEST_ARGPARSE_DEPTH"`
+	}
+
+	// defaults and env are only shown, not applied
+	t.Setenv("TEST_ARGPARSE_DEPTH", "3")
+	s := S{}
+	parser := FromStruct(&s)
+	parser.Width = 80
+	inv, err := parser.ParseInvocation()
+	assertError(t, false, err)
+	assertEqual(t, "", s.Level)
+	assertEqual(t, 0, s.Depth)
+	assertEqual(t, false, inv.IsSet("depth"))
+
+	usage := parser.Usage()
+	assertEqual(t, true, strings.Contains(usage, "(default: info)"))
+	assertEqual(t, true, strings.Contains(usage, "(default: 1, env: TEST_ARGPARSE_DEPTH)"))
+}
+
+func TestHelpOutput(t *testing.T) {
+	stdout := &strings.Builder{}
+	stderr := &strings.Builder{}
+	code := -1
+
+	parser := NewWithDefaults()
+	parser.Name = "tool"
+	parser.Stdout = stdout
+	parser.Stderr = stderr
+	parser.Exit = func(c int) { code = c }
+
+	sub := NewWithDefaults()
+	sub.Name = "tool sub"
+	parser.AddSubParser("sub", sub)
+
+	err := parser.Parse("-h", "sub")
+	if err != ErrHelp {
+		t.Errorf("expected ErrHelp; got %v", err)
+	}
+	assertEqual(t, 0, code)
+	assertEqual(t, parser.Usage(), stdout.String())
+
+	stdout.Reset()
+	err = parser.Parse("sub", "--help")
+	if err != ErrHelp {
+		t.Errorf("expected ErrHelp; got %v", err)
+	}
+	assertEqual(t, sub.Usage(), stdout.String())
+
+	sub.PrintError(parser.Parse("--unknown"))
+	assertEqual(t, "error: unknown option \"unknown\"\n", stderr.String())
+
+	// an option named h added before the subparser replaces -h, --help
+	host := ""
+	before := New()
+	before.AddOptionWithAlias(String("h", &host), "host")
+	parser.AddSubParser("before", before)
+	assertError(t, false, parser.Parse("before", "-h", "example.com"))
+	assertEqual(t, "example.com", host)
+	assertError(t, true, parser.Parse("before", "--help"))
+
+	// and panics after, when -h, --help was propagated
+	after := New()
+	parser.AddSubParser("after", after)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Errorf("expected -h to conflict with the propagated help")
+			}
+		}()
+		after.AddOptionWithAlias(String("h", &host), "host")
+	}()
+}
+
+func TestHiddenDeprecated(t *testing.T) {
+	type S struct {
+		OldName string `deprecated:"use --name" replacement:"name" hidden:"true"`
+		Name    string
+		Debug   bool `hidden:"true"`
+		Legacy  bool `deprecated:"it has no effect"`
+	}
+
+	stderr := &strings.Builder{}
+	s := S{}
+	parser := New()
+	parser.Width = 80
+	parser.Name = "tool"
+	parser.Stderr = stderr
+	parser.LoadStruct(&s)
+
+	assertEqual(t, "usage: tool [--name var] [--legacy] \n"+
+		"\noptions:\n"+
+		"    --name var\n"+
+		"    --legacy\n", parser.Usage())
+	candidates, _ := parser.Complete("--")
+	assertSliceEqual(t, []string{"--name", "--legacy"}, candidates)
+
+	inv, err := parser.ParseInvocation("--old-name", "a", "--debug", "--legacy", "--old-name", "b", "--legacy")
+	assertError(t, false, err)
+	assertEqual(t, "b", s.Name)
+	assertEqual(t, "", s.OldName)
+	assertEqual(t, true, s.Debug)
+	assertEqual(t, true, s.Legacy)
+	assertSliceEqual(t, []string{"a", "b"}, inv.Values("name"))
+	assertEqual(t, "warning: option --old-name is deprecated: use --name\n"+
+		"warning: option --legacy is deprecated: it has no effect\n", stderr.String())
+
+	// invalid replacements panic when added, not when parsing
+	for _, opt := range []Option{
+		{Name: "old", Nargs: 1, Replacement: "missing"},
+		{Name: "old", Replacement: "name"},
+	} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected %q to panic", opt.Replacement)
+				}
+			}()
+			parser.AddOption(opt)
+		}()
+	}
+}
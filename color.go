@@ -0,0 +1,116 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+type ColorMode int
+
+const (
+	// color output written to a terminal, unless $NO_COLOR is set
+	ColorAuto ColorMode = iota
+	ColorAlways
+	ColorNever
+)
+
+// ansi SGR parameters of each part of the usage and errors, e.g. "1" for
+// bold or "1;31" for bold red. empty parts are not styled
+type Theme struct {
+	Heading string
+	Option  string
+	Metavar string
+	Command string
+	Error   string
+	Warning string
+}
+
+var DefaultTheme = Theme{
+	Heading: "1",
+	Option:  "36",
+	Metavar: "33",
+	Command: "32",
+	Error:   "1;31",
+	Warning: "1;33",
+}
+
+// return the theme of a, the theme of the closest parent that has one or
+// DefaultTheme
+func (a *ArgParser) theme() Theme {
+	for p := a; p != nil; p = p.parent {
+		if p.Theme != nil {
+			return *p.Theme
+		}
+	}
+	return DefaultTheme
+}
+
+// return whether output written to w is colored
+func (a *ArgParser) colorize(w io.Writer) bool {
+	for p := a; p != nil; p = p.parent {
+		switch p.Color {
+		case ColorAlways:
+			return true
+		case ColorNever:
+			return false
+		}
+	}
+	if len(os.Getenv("NO_COLOR")) > 0 {
+		return false
+	}
+	f, ok := w.(*os.File)
+	return ok && isTerminal(f)
+}
+
+func isTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// return s styled with the SGR parameters sgr
+func style(sgr, s string) string {
+	if len(sgr) == 0 || len(s) == 0 {
+		return s
+	}
+	return "\x1b[" + sgr + "m" + s + "\x1b[0m"
+}
+
+// return a function styling a part of the theme, or returning s unchanged
+// if color is false
+func (t Theme) styler(color bool) func(part, s string) string {
+	return func(part, s string) string {
+		if !color {
+			return s
+		}
+		switch part {
+		case "heading":
+			return style(t.Heading, s)
+		case "option":
+			return style(t.Option, s)
+		case "metavar":
+			return style(t.Metavar, s)
+		case "command":
+			return style(t.Command, s)
+		case "error":
+			return style(t.Error, s)
+		case "warning":
+			return style(t.Warning, s)
+		default:
+			panic(fmt.Sprintf("unknown theme part %q", part))
+		}
+	}
+}
+
+// writes err to w prefixed by "error: "
+func (a *ArgParser) WriteError(w io.Writer, err error) error {
+	styler := a.theme().styler(a.colorize(w))
+	_, werr := io.WriteString(w, styler("error", "error:")+" "+strings.TrimRight(err.Error(), "\n")+"\n")
+	return werr
+}
+
+// writes err to the Stderr of a. see WriteError
+func (a *ArgParser) PrintError(err error) {
+	a.WriteError(a.stderr(), err)
+}
@@ -0,0 +1,51 @@
+package argparse
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColor(t *testing.T) {
+	parser := New()
+	parser.Width = 80
+	parser.Name = "tool"
+	parser.AddOption(Option{Name: "c", Nargs: 1, Metavar: "file", Description: "config file"})
+	parser.AddSubParserWithAlias("remote", New(), "r")
+
+	plain := parser.Usage()
+	assertEqual(t, false, strings.Contains(plain, "\x1b"))
+
+	parser.Color = ColorAlways
+	parser.Theme = &Theme{Heading: "1", Option: "36", Metavar: "33"}
+	assertEqual(t, "\x1b[1musage:\x1b[0m tool [-c file] <command> \n"+
+		"\n\x1b[1moptions:\x1b[0m\n"+
+		"    \x1b[36m-c\x1b[0m \x1b[33mfile\x1b[0m        config file \n"+
+		"\n\x1b[1mcommands:\x1b[0m\n"+
+		"    remote (r)\n", parser.Usage())
+
+	// colored output is aligned as the plain one
+	lines := strings.Split(parser.Usage(), "\n")
+	for i, line := range strings.Split(plain, "\n") {
+		assertEqual(t, displayWidth(line), displayWidth(lines[i]))
+	}
+
+	b := &strings.Builder{}
+	sub := parser.subparsers["remote"]
+	assertError(t, false, sub.WriteError(b, errors.New("unknown option")))
+	assertEqual(t, "error: unknown option\n", b.String())
+
+	parser.Theme = nil
+	b.Reset()
+	assertError(t, false, sub.WriteError(b, errors.New("unknown option")))
+	assertEqual(t, "\x1b[1;31merror:\x1b[0m unknown option\n", b.String())
+
+	parser.Color = ColorAuto
+	assertEqual(t, false, parser.colorize(b))
+	f, err := os.Open(os.DevNull)
+	assertError(t, false, err)
+	defer f.Close()
+	t.Setenv("NO_COLOR", "1")
+	assertEqual(t, false, parser.colorize(f))
+}
@@ -0,0 +1,231 @@
+package argparse
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// a parser of the tree along with the command path leading to it
+type completionNode struct {
+	parser *ArgParser
+	// program name followed by canonical subparser names
+	path []string
+}
+
+func (n completionNode) key() string {
+	return strings.Join(n.path, " ")
+}
+
+// return every parser of the tree, parents before children
+func (a *ArgParser) completionNodes() []completionNode {
+	nodes := make([]completionNode, 0)
+	var walk func(p *ArgParser, path []string)
+	walk = func(p *ArgParser, path []string) {
+		nodes = append(nodes, completionNode{parser: p, path: path})
+		for _, name := range p.subParserNames(true) {
+			walk(p.subparsers[name], append(path[:len(path):len(path)], name))
+		}
+	}
+	walk(a, a.commandPath()[:1])
+	return nodes
+}
+
+// return options accepted by the parser, including inherited ones
+func (a *ArgParser) completionOptions() [][]*Option {
+	aliases := make([][]*Option, 0)
+	for _, alias := range append(a.Aliases(), a.globalAliases()...) {
+		if !alias[0].Positional && !alias[0].Hidden {
+			aliases = append(aliases, alias)
+		}
+	}
+	return aliases
+}
+
+// return words completed when no option argument is expected: options,
+// visible subparsers and positional choices
+func (a *ArgParser) completionWords() []string {
+	words := make([]string, 0)
+	for _, alias := range a.completionOptions() {
+		for _, opt := range alias {
+			words = append(words, opt.String())
+		}
+	}
+	for _, opt := range a.pos {
+		words = append(words, opt.Choices...)
+	}
+	words = append(words, a.subParserNames(false)...)
+	return words
+}
+
+func (a *ArgParser) BashCompletion(w io.Writer) error {
+	nodes := a.completionNodes()
+	prog := nodes[0].key()
+	fn := "_" + shellIdentifier(prog)
+
+	dynamic := a.dynamicCompletion()
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# bash completion for %s\n\n", prog)
+	if dynamic {
+		fmt.Fprintf(b, bashDynamic, fn)
+	}
+	fmt.Fprintf(b, "%s() {\n", fn)
+	b.WriteString("\tlocal cur prev cmd i\n")
+	b.WriteString("\tCOMPREPLY=()\n")
+	b.WriteString("\tcur=\"${COMP_WORDS[COMP_CWORD]}\"\n")
+	b.WriteString("\tprev=\"${COMP_WORDS[COMP_CWORD-1]}\"\n")
+	fmt.Fprintf(b, "\tcmd=%s\n\n", shellQuote(prog))
+
+	// find the selected parser, skipping option arguments
+	b.WriteString("\tfor ((i = 1; i < COMP_CWORD; i++)); do\n")
+	b.WriteString("\t\tcase \"$cmd ${COMP_WORDS[i]}\" in\n")
+	for _, node := range nodes {
+		for _, name := range node.parser.subParserNames(true) {
+			sub := node.parser.subparsers[name]
+			patterns := make([]string, 0)
+			for _, alias := range append([]string{name}, sub.subaliases...) {
+				patterns = append(patterns, shellQuote(node.key()+" "+alias))
+			}
+			fmt.Fprintf(b, "\t\t%s) cmd=%s ;;\n", strings.Join(patterns, "|"), shellQuote(node.key()+" "+name))
+		}
+		for _, alias := range node.parser.completionOptions() {
+			if alias[0].Nargs == 0 {
+				continue
+			}
+			fmt.Fprintf(b, "\t\t%s) ((i += %d)) ;;\n", bashPatterns(node, alias), alias[0].Nargs)
+		}
+	}
+	b.WriteString("\t\tesac\n")
+	b.WriteString("\tdone\n\n")
+
+	// complete option arguments
+	b.WriteString("\tcase \"$cmd $prev\" in\n")
+	for _, node := range nodes {
+		for _, alias := range node.parser.completionOptions() {
+			if alias[0].Nargs == 0 {
+				continue
+			}
+			if dynamic && alias[0].Completer != nil {
+				fmt.Fprintf(b, "\t%s) %s_dynamic; return ;;\n", bashPatterns(node, alias), fn)
+			} else if len(alias[0].Choices) > 0 {
+				fmt.Fprintf(b, "\t%s) COMPREPLY=($(compgen -W %s -- \"$cur\")); return ;;\n", bashPatterns(node, alias), shellQuote(strings.Join(alias[0].Choices, " ")))
+			} else {
+				fmt.Fprintf(b, "\t%s) COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n", bashPatterns(node, alias))
+			}
+		}
+	}
+	b.WriteString("\tesac\n\n")
+
+	b.WriteString("\tcase \"$cmd\" in\n")
+	for _, node := range nodes {
+		if dynamic && node.parser.hasPositionalCompleters() {
+			fmt.Fprintf(b, "\t%s) %s_dynamic ;;\n", shellQuote(node.key()), fn)
+			continue
+		}
+		fmt.Fprintf(b, "\t%s) COMPREPLY=($(compgen -W %s -- \"$cur\")) ;;\n", shellQuote(node.key()), shellQuote(strings.Join(node.parser.completionWords(), " ")))
+	}
+	b.WriteString("\tesac\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(b, "complete -F %s %s\n", fn, shellQuote(prog))
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// completes using the "__complete" subparser
+const bashDynamic = `%s_dynamic() {
+	local out directive line ext
+	out=$("${COMP_WORDS[0]}" __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null) || return
+	directive=${out##*:}
+	COMPREPLY=()
+	if ((directive & 4)); then
+		local exts=()
+		while IFS= read -r line; do
+			[[ $line == :* ]] || exts+=("$line")
+		done <<< "$out"
+		if ((${#exts[@]} == 0)); then
+			COMPREPLY=($(compgen -f -- "$cur"))
+		else
+			for ext in "${exts[@]}"; do
+				COMPREPLY+=($(compgen -f -X "!*.$ext" -- "$cur"))
+			done
+			COMPREPLY+=($(compgen -d -- "$cur"))
+		fi
+	elif ((directive & 8)); then
+		COMPREPLY=($(compgen -d -- "$cur"))
+	else
+		while IFS= read -r line; do
+			[[ $line == :* ]] || COMPREPLY+=("${line%%%%$'\t'*}")
+		done <<< "$out"
+	fi
+	if ((directive & 1)); then
+		compopt -o nospace 2>/dev/null
+	fi
+}
+
+`
+
+func bashPatterns(node completionNode, alias []*Option) string {
+	patterns := make([]string, 0, len(alias))
+	for _, opt := range alias {
+		patterns = append(patterns, shellQuote(node.key()+" "+opt.String()))
+	}
+	return strings.Join(patterns, "|")
+}
+
+// adds a "completion" subparser with a subparser for each supported shell
+// that writes the completion script of a to stdout, and the hidden
+// "__complete" subparser used by the scripts for options with a Completer
+func (a *ArgParser) AddCompletionSubParser() {
+	completion := New()
+	completion.Description = "generates shell completion scripts"
+	completion.SubParserRequired = true
+	a.AddSubParser("completion", completion)
+
+	shells := []struct {
+		name   string
+		script func(io.Writer) error
+	}{
+		{"bash", a.BashCompletion},
+		{"zsh", a.ZshCompletion},
+		{"fish", a.FishCompletion},
+		{"powershell", a.PowerShellCompletion},
+	}
+
+	complete := New()
+	complete.Hidden = true
+	complete.RawArgs = true
+	complete.Run = func(ctx context.Context, inv *Invocation) error {
+		candidates, directive := a.Complete(inv.Args...)
+		return writeCompletion(inv.Stdout(), candidates, directive)
+	}
+	a.AddSubParser("__complete", complete)
+
+	for _, shell := range shells {
+		script := shell.script
+		sub := New()
+		sub.Description = "generates " + shell.name + " completion script"
+		sub.Run = func(ctx context.Context, inv *Invocation) error {
+			return script(inv.Stdout())
+		}
+		completion.AddSubParser(shell.name, sub)
+	}
+}
+
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+func shellIdentifier(s string) string {
+	b := &strings.Builder{}
+	for _, c := range s {
+		if (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') || c == '_' {
+			b.WriteRune(c)
+		} else {
+			b.WriteRune('_')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,196 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// tells the shell how to handle completion candidates
+type CompletionDirective int
+
+const (
+	// dont add a space after the completed word
+	CompleteNoSpace CompletionDirective = 1 << iota
+	// dont fall back to file completion
+	CompleteNoFiles
+	// complete file names. candidates, if any, are the allowed extensions
+	CompleteFiles
+	// complete directory names
+	CompleteDirs
+)
+
+type completionState struct {
+	// option still waiting for arguments
+	pending *Option
+	// positional index of the innermost parser
+	pindex int
+}
+
+// sets how the shell handles the candidates returned by a completer.
+// extensions restrict CompleteFiles to files ending with them
+func (c *Context) CompleteDirective(d CompletionDirective, extensions ...string) {
+	c.directive |= d
+	c.extensions = append(c.extensions, extensions...)
+}
+
+// completer of files with one of the extensions (without the leading dot),
+// or any file if none is given
+func CompleteFileExt(extensions ...string) func(ctx *Context, prefix string) []string {
+	return func(ctx *Context, prefix string) []string {
+		ctx.CompleteDirective(CompleteFiles, extensions...)
+		return nil
+	}
+}
+
+// completer of directories
+func CompleteDirectories(ctx *Context, prefix string) []string {
+	ctx.CompleteDirective(CompleteDirs)
+	return nil
+}
+
+// completer of a fixed list of words
+func CompleteWords(words ...string) func(ctx *Context, prefix string) []string {
+	return func(ctx *Context, prefix string) []string {
+		return words
+	}
+}
+
+// parses args leniently, without calling callbacks, and returns the
+// completion candidates of the last one
+func (a *ArgParser) Complete(args ...string) ([]string, CompletionDirective) {
+	if len(args) == 0 {
+		args = []string{""}
+	}
+	prefix := args[len(args)-1]
+
+	inv := newInvocation(a, nil)
+	inv.completion = &completionState{}
+	a.parse(inv, args[:len(args)-1]...)
+
+	leaf := inv.Leaf()
+	if len(leaf.Plugin) > 0 || leaf.Parser.RawArgs {
+		return []string{}, 0
+	}
+	ctx := &Context{parser: leaf.Parser, inv: leaf}
+
+	if opt := inv.completion.pending; opt != nil {
+		return ctx.complete(opt, prefix)
+	}
+
+	if strings.HasPrefix(prefix, "--") {
+		if i := strings.IndexRune(prefix, '='); i > 0 {
+			opt, _ := leaf.Parser.findOption(prefix[2:i])
+			if opt == nil || opt.Nargs == 0 {
+				return []string{}, CompleteNoFiles
+			}
+			candidates, directive := ctx.complete(opt, prefix[i+1:])
+			if directive&(CompleteFiles|CompleteDirs) == 0 {
+				for j := range candidates {
+					candidates[j] = prefix[:i+1] + candidates[j]
+				}
+			}
+			return candidates, directive
+		}
+	}
+
+	if strings.HasPrefix(prefix, "-") {
+		candidates := make([]string, 0)
+		for _, alias := range leaf.Parser.completionOptions() {
+			for _, opt := range alias {
+				candidates = append(candidates, candidate(opt.String(), alias[0].Description))
+			}
+		}
+		return filterCandidates(candidates, prefix), CompleteNoFiles
+	}
+
+	candidates := make([]string, 0)
+	for _, name := range leaf.Parser.subParserNames(false) {
+		candidates = append(candidates, candidate(name, leaf.Parser.subparsers[name].Description))
+	}
+	candidates = filterCandidates(candidates, prefix)
+
+	pos := leaf.Parser.pos
+	pindex := inv.completion.pindex
+	if pindex >= len(pos) && len(pos) > 0 && pos[len(pos)-1].Nargs < 0 {
+		pindex = len(pos) - 1
+	}
+	if pindex < len(pos) {
+		tmp, directive := ctx.complete(pos[pindex], prefix)
+		return append(candidates, tmp...), directive
+	}
+
+	return candidates, CompleteNoFiles
+}
+
+// return candidates for an argument of opt
+func (c *Context) complete(opt *Option, prefix string) ([]string, CompletionDirective) {
+	if opt.Completer != nil {
+		c.opt = opt
+		candidates := filterCandidates(opt.Completer(c, prefix), prefix)
+		c.opt = nil
+		if c.directive&(CompleteFiles|CompleteDirs) != 0 {
+			return c.extensions, c.directive
+		}
+		return candidates, c.directive | CompleteNoFiles
+	}
+	if len(opt.Choices) > 0 {
+		return filterCandidates(opt.Choices, prefix), CompleteNoFiles
+	}
+	return []string{}, CompleteFiles
+}
+
+func candidate(value, description string) string {
+	if len(description) == 0 {
+		return value
+	}
+	return value + "\t" + flatten(description)
+}
+
+func filterCandidates(candidates []string, prefix string) []string {
+	r := make([]string, 0, len(candidates))
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			r = append(r, c)
+		}
+	}
+	return r
+}
+
+// writes candidates one per line followed by ":" and the directive
+func writeCompletion(w io.Writer, candidates []string, directive CompletionDirective) error {
+	b := &strings.Builder{}
+	for _, c := range candidates {
+		b.WriteString(c + "\n")
+	}
+	fmt.Fprintf(b, ":%d\n", directive)
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// return whether the scripts complete using the "__complete" subparser,
+// that is whether it was added by AddCompletionSubParser and any option or
+// positional of the tree has a completer. otherwise completers are ignored
+// and options are completed like ones without a completer
+func (a *ArgParser) dynamicCompletion() bool {
+	if _, ok := a.subparsers["__complete"]; !ok {
+		return false
+	}
+	for _, node := range a.completionNodes() {
+		for _, opt := range node.parser.Options() {
+			if opt.Completer != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (a *ArgParser) hasPositionalCompleters() bool {
+	for _, opt := range a.pos {
+		if opt.Completer != nil {
+			return true
+		}
+	}
+	return false
+}
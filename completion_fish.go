@@ -0,0 +1,141 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (a *ArgParser) FishCompletion(w io.Writer) error {
+	nodes := a.completionNodes()
+	prog := nodes[0].key()
+	fn := "__" + shellIdentifier(prog) + "_command"
+	dynamic := ""
+	if a.dynamicCompletion() {
+		dynamic = "__" + shellIdentifier(prog) + "_dynamic"
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# fish completion for %s\n\n", prog)
+	if len(dynamic) > 0 {
+		fmt.Fprintf(b, fishDynamic, dynamic)
+	}
+
+	// prints the selected parser, skipping option arguments
+	fmt.Fprintf(b, "function %s\n", fn)
+	b.WriteString("\tset -l words (commandline -opc)\n")
+	fmt.Fprintf(b, "\tset -l cmd %s\n", fishQuote(prog))
+	b.WriteString("\tset -e words[1]\n")
+	b.WriteString("\twhile set -q words[1]\n")
+	b.WriteString("\t\tswitch \"$cmd $words[1]\"\n")
+	for _, node := range nodes {
+		for _, name := range node.parser.subParserNames(true) {
+			sub := node.parser.subparsers[name]
+			patterns := make([]string, 0)
+			for _, alias := range append([]string{name}, sub.subaliases...) {
+				patterns = append(patterns, fishQuote(node.key()+" "+alias))
+			}
+			fmt.Fprintf(b, "\t\t\tcase %s\n", strings.Join(patterns, " "))
+			fmt.Fprintf(b, "\t\t\t\tset cmd %s\n", fishQuote(node.key()+" "+name))
+		}
+		for _, alias := range node.parser.completionOptions() {
+			if alias[0].Nargs == 0 {
+				continue
+			}
+			patterns := make([]string, 0, len(alias))
+			for _, opt := range alias {
+				patterns = append(patterns, fishQuote(node.key()+" "+opt.String()))
+			}
+			fmt.Fprintf(b, "\t\t\tcase %s\n", strings.Join(patterns, " "))
+			fmt.Fprintf(b, "\t\t\t\tset -e words[1..%d]\n", alias[0].Nargs)
+		}
+	}
+	b.WriteString("\t\tend\n")
+	b.WriteString("\t\tset -e words[1]\n")
+	b.WriteString("\tend\n")
+	b.WriteString("\techo $cmd\n")
+	b.WriteString("end\n\n")
+
+	fmt.Fprintf(b, "complete -c %s -e\n", fishQuote(prog))
+	for _, node := range nodes {
+		complete := fmt.Sprintf("complete -c %s -n %s", fishQuote(prog), fishQuote("test ("+fn+") = "+fishQuote(node.key())))
+
+		if len(node.parser.pos) == 0 {
+			b.WriteString(complete + " -f\n")
+		}
+
+		for _, alias := range node.parser.completionOptions() {
+			line := complete
+			for _, opt := range alias {
+				if len(opt.Name) == 1 {
+					line += " -s " + fishQuote(opt.Name)
+				} else {
+					line += " -l " + fishQuote(opt.Name)
+				}
+			}
+			if base := alias[0]; base.Nargs > 0 {
+				if base.Completer != nil && len(dynamic) > 0 {
+					line += " -x -a " + fishQuote("("+dynamic+")")
+				} else if len(base.Choices) > 0 {
+					line += " -x -a " + fishQuote(strings.Join(base.Choices, " "))
+				} else {
+					line += " -r -F"
+				}
+			}
+			if len(alias[0].Description) > 0 {
+				line += " -d " + fishQuote(flatten(alias[0].Description))
+			}
+			b.WriteString(line + "\n")
+		}
+
+		for _, opt := range node.parser.pos {
+			if opt.Completer != nil && len(dynamic) > 0 {
+				fmt.Fprintf(b, "%s -a %s\n", complete, fishQuote("("+dynamic+")"))
+			} else if len(opt.Choices) > 0 {
+				fmt.Fprintf(b, "%s -a %s\n", complete, fishQuote(strings.Join(opt.Choices, " ")))
+			}
+		}
+
+		for _, name := range node.parser.subParserNames(false) {
+			line := complete + " -a " + fishQuote(name)
+			if sub := node.parser.subparsers[name]; len(sub.Description) > 0 {
+				line += " -d " + fishQuote(flatten(sub.Description))
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// completes using the "__complete" subparser
+const fishDynamic = `function %s
+	set -l args (commandline -opc)
+	set -l prog $args[1]
+	set -e args[1]
+	set -l current (commandline -ct)
+	set -l out ($prog __complete $args "$current" 2>/dev/null)
+	or return
+	set -l directive (string replace -r '^:' '' -- $out[-1])
+	set -e out[-1]
+	if test (math "bitand($directive, 4)") -ne 0
+		if set -q out[1]
+			for ext in $out
+				__fish_complete_suffix .$ext
+			end
+		else
+			__fish_complete_path "$current"
+		end
+	else if test (math "bitand($directive, 8)") -ne 0
+		__fish_complete_directories "$current"
+	else
+		printf '%%s\n' $out
+	end
+end
+
+`
+
+func fishQuote(s string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(s) + "'"
+}
@@ -0,0 +1,163 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (a *ArgParser) PowerShellCompletion(w io.Writer) error {
+	nodes := a.completionNodes()
+	prog := nodes[0].key()
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "# powershell completion for %s\n\n", prog)
+	fmt.Fprintf(b, "Register-ArgumentCompleter -Native -CommandName %s -ScriptBlock {\n", psQuote(prog))
+	b.WriteString("\tparam($wordToComplete, $commandAst, $cursorPosition)\n\n")
+	b.WriteString("\t$words = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })\n")
+	fmt.Fprintf(b, "\t$cmd = %s\n\n", psQuote(prog))
+
+	// find the selected parser, skipping option arguments
+	b.WriteString("\tfor ($i = 1; $i -lt $words.Count; $i++) {\n")
+	b.WriteString("\t\tswitch -casesensitive (\"$cmd \" + $words[$i]) {\n")
+	for _, node := range nodes {
+		for _, name := range node.parser.subParserNames(true) {
+			sub := node.parser.subparsers[name]
+			for _, alias := range append([]string{name}, sub.subaliases...) {
+				fmt.Fprintf(b, "\t\t\t%s { $cmd = %s }\n", psQuote(node.key()+" "+alias), psQuote(node.key()+" "+name))
+			}
+		}
+		for _, alias := range node.parser.completionOptions() {
+			if alias[0].Nargs == 0 {
+				continue
+			}
+			for _, opt := range alias {
+				fmt.Fprintf(b, "\t\t\t%s { $i += %d }\n", psQuote(node.key()+" "+opt.String()), alias[0].Nargs)
+			}
+		}
+	}
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t}\n\n")
+
+	b.WriteString("\t$prev = \"$cmd \" + $words[-1]\n")
+
+	if a.dynamicCompletion() {
+		// options and parsers with positionals with a Completer are
+		// completed using the "__complete" subparser
+		b.WriteString("\t$dynamic = switch -casesensitive ($prev) {\n")
+		for _, node := range nodes {
+			for _, alias := range node.parser.completionOptions() {
+				if alias[0].Nargs == 0 || alias[0].Completer == nil {
+					continue
+				}
+				for _, opt := range alias {
+					fmt.Fprintf(b, "\t\t%s { $true }\n", psQuote(node.key()+" "+opt.String()))
+				}
+			}
+		}
+		b.WriteString("\t}\n")
+		b.WriteString("\tif (-not $dynamic) {\n")
+		b.WriteString("\t\t$dynamic = switch -casesensitive ($cmd) {\n")
+		for _, node := range nodes {
+			if node.parser.hasPositionalCompleters() {
+				fmt.Fprintf(b, "\t\t\t%s { $true }\n", psQuote(node.key()))
+			}
+		}
+		b.WriteString("\t\t}\n")
+		b.WriteString("\t}\n")
+		b.WriteString(psDynamic)
+	}
+
+	// complete option arguments. options without choices fall back to
+	// the default (file) completion
+	b.WriteString("\t$results = switch -casesensitive ($prev) {\n")
+	for _, node := range nodes {
+		for _, alias := range node.parser.completionOptions() {
+			if alias[0].Nargs == 0 {
+				continue
+			}
+			for _, opt := range alias {
+				if len(alias[0].Choices) == 0 {
+					fmt.Fprintf(b, "\t\t%s { return }\n", psQuote(node.key()+" "+opt.String()))
+					continue
+				}
+				fmt.Fprintf(b, "\t\t%s {\n", psQuote(node.key()+" "+opt.String()))
+				for _, choice := range alias[0].Choices {
+					b.WriteString("\t\t\t" + psResult(choice, "ParameterValue", "") + "\n")
+				}
+				b.WriteString("\t\t}\n")
+			}
+		}
+	}
+	b.WriteString("\t}\n\n")
+
+	b.WriteString("\tif ($null -eq $results) {\n")
+	b.WriteString("\t\t$results = switch -casesensitive ($cmd) {\n")
+	for _, node := range nodes {
+		fmt.Fprintf(b, "\t\t\t%s {\n", psQuote(node.key()))
+		for _, alias := range node.parser.completionOptions() {
+			for _, opt := range alias {
+				b.WriteString("\t\t\t\t" + psResult(opt.String(), "ParameterName", alias[0].Description) + "\n")
+			}
+		}
+		for _, opt := range node.parser.pos {
+			for _, choice := range opt.Choices {
+				b.WriteString("\t\t\t\t" + psResult(choice, "ParameterValue", "") + "\n")
+			}
+		}
+		for _, name := range node.parser.subParserNames(false) {
+			b.WriteString("\t\t\t\t" + psResult(name, "Command", node.parser.subparsers[name].Description) + "\n")
+		}
+		b.WriteString("\t\t\t}\n")
+	}
+	b.WriteString("\t\t}\n")
+	b.WriteString("\t}\n\n")
+
+	b.WriteString("\t$results | Where-Object { $_.CompletionText -like \"$wordToComplete*\" }\n")
+	b.WriteString("}\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+const psDynamic = `	if ($dynamic) {
+		$rest = @($words | Select-Object -Skip 1)
+		$out = @(& $words[0] __complete @rest $wordToComplete 2>$null)
+		if ($out.Count -eq 0) {
+			return
+		}
+		$directive = [int]$out[-1].TrimStart(':')
+		$values = @($out | Select-Object -SkipLast 1)
+		$dir = if ($wordToComplete) { Split-Path -Parent $wordToComplete } else { '' }
+		if ($directive -band 12) {
+			Get-ChildItem -Path "$wordToComplete*" | Where-Object {
+				$_.PSIsContainer -or (($directive -band 4) -and ($values.Count -eq 0 -or $values -contains $_.Extension.TrimStart('.')))
+			} | ForEach-Object {
+				$path = if ($dir) { Join-Path $dir $_.Name } else { $_.Name }
+				[System.Management.Automation.CompletionResult]::new($path, $path, 'ProviderItem', $path)
+			}
+			return
+		}
+		$values | ForEach-Object {
+			$value, $description = $_ -split "` + "`" + `t", 2
+			if (-not $description) {
+				$description = $value
+			}
+			[System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+		}
+		return
+	}
+
+`
+
+func psResult(text, kind, description string) string {
+	tooltip := flatten(description)
+	if len(tooltip) == 0 {
+		tooltip = text
+	}
+	return fmt.Sprintf("[System.Management.Automation.CompletionResult]::new(%s, %s, '%s', %s)", psQuote(text), psQuote(text), kind, psQuote(tooltip))
+}
+
+func psQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
@@ -0,0 +1,239 @@
+package argparse
+
+import (
+	"bytes"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+func completionTestParser() *ArgParser {
+	parser := New()
+	parser.Name = "./tool"
+	parser.AddOptionWithAlias(Option{Name: "v", Description: "verbose output", Persistent: true}, "verbose")
+	parser.AddOption(Option{Name: "color", Nargs: 1, Metavar: "when", Description: "colorize output", Choices: []string{"always", "never"}})
+	parser.AddOptionWithAlias(Option{Name: "c", Nargs: 1, Metavar: "file", Description: "config file"}, "config")
+
+	remote := New()
+	remote.Description = "manage remotes"
+	parser.AddSubParserWithAlias("remote", remote, "r")
+
+	add := New()
+	add.Description = "add a remote"
+	add.AddOption(Option{Name: "fetch", Description: "fetch after adding"})
+	add.AddOption(Option{Name: "name", Positional: true, Nargs: 1, Required: true})
+	remote.AddSubParser("add", add)
+
+	log := New()
+	log.Description = "show logs"
+	log.AddOption(Option{Name: "format", Nargs: 1, Choices: []string{"short", "full"}})
+	log.AddOption(Option{Name: "level", Positional: true, Nargs: 1, Choices: []string{"debug", "info"}})
+	parser.AddSubParser("log", log)
+
+	return parser
+}
+
+func assertGolden(t *testing.T, name string, actual []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.WriteFile(path, actual, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(expected, actual) {
+		t.Errorf("%s does not match:\n%s", path, actual)
+	}
+}
+
+func TestBashCompletion(t *testing.T) {
+	b := &bytes.Buffer{}
+	assertError(t, false, completionTestParser().BashCompletion(b))
+	assertGolden(t, "completion.bash", b.Bytes())
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not found")
+	}
+
+	complete := func(words ...string) []string {
+		t.Helper()
+		script := b.String() + `
+COMP_WORDS=("$@")
+COMP_CWORD=$(($# - 1))
+_tool
+printf '%s\n' "${COMPREPLY[@]}"
+`
+		out, err := exec.Command("bash", append([]string{"-c", script, "bash"}, words...)...).Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return strings.Fields(string(out))
+	}
+
+	assertSliceEqual(t, []string{"-v", "--verbose", "--color", "-c", "--config", "remote", "log"}, complete("tool", ""))
+	assertSliceEqual(t, []string{"--verbose"}, complete("tool", "--v"))
+	assertSliceEqual(t, []string{"always", "never"}, complete("tool", "--color", ""))
+	assertSliceEqual(t, []string{"-v", "--verbose", "add"}, complete("tool", "-c", "remote", "r", ""))
+	assertSliceEqual(t, []string{"--fetch", "-v", "--verbose"}, complete("tool", "r", "add", "-"))
+	assertSliceEqual(t, []string{"full"}, complete("tool", "log", "--format", "f"))
+	assertSliceEqual(t, []string{"debug"}, complete("tool", "log", "d"))
+}
+
+func TestShellCompletions(t *testing.T) {
+	parser := completionTestParser()
+	scripts := map[string]func(io.Writer) error{
+		"completion.zsh":  parser.ZshCompletion,
+		"completion.fish": parser.FishCompletion,
+		"completion.ps1":  parser.PowerShellCompletion,
+	}
+
+	for name, script := range scripts {
+		b := &bytes.Buffer{}
+		assertError(t, false, script(b))
+		assertGolden(t, name, b.Bytes())
+	}
+}
+
+func TestCompletionSubParser(t *testing.T) {
+	parser := completionTestParser()
+	parser.AddCompletionSubParser()
+
+	for _, shell := range []string{"bash", "zsh", "fish", "powershell"} {
+		inv, err := parser.ParseInvocation("completion", shell)
+		assertError(t, false, err)
+		assertEqual(t, shell, inv.Leaf().Parser.subname)
+	}
+
+	assertError(t, true, parser.Parse("completion"))
+}
+
+func dynamicTestParser() *ArgParser {
+	parser := New()
+	parser.Name = "kube"
+	parser.AddOptionWithAlias(Option{Name: "n", Nargs: 1, Description: "namespace", Persistent: true}, "namespace")
+	parser.AddOption(Option{Name: "context", Nargs: 1, Description: "context to use", Completer: CompleteWords("prod\tproduction cluster", "dev", "staging")})
+	parser.AddOption(Option{Name: "f", Nargs: 1, Completer: CompleteFileExt("yaml", "yml")})
+	parser.AddOption(Option{Name: "dir", Nargs: 1, Completer: CompleteDirectories})
+
+	logs := New()
+	logs.Description = "print logs"
+	logs.AddOption(Option{Name: "follow"})
+	logs.AddOption(Option{Name: "pod", Positional: true, Nargs: 1, Completer: func(ctx *Context, prefix string) []string {
+		ctx.CompleteDirective(CompleteNoSpace)
+		return []string{"web-" + ctx.Invocation().Value("n"), "db-" + ctx.Invocation().Value("n")}
+	}})
+	parser.AddSubParser("logs", logs)
+	parser.AddSubParser("version", New())
+
+	return parser
+}
+
+func TestComplete(t *testing.T) {
+	parser := dynamicTestParser()
+
+	complete := func(expected []string, directive CompletionDirective, args ...string) {
+		t.Helper()
+		candidates, d := parser.Complete(args...)
+		assertSliceEqual(t, expected, candidates)
+		assertEqual(t, directive, d)
+	}
+
+	complete([]string{"logs\tprint logs", "version"}, CompleteNoFiles)
+	complete([]string{"--namespace\tnamespace", "--context\tcontext to use", "--dir"}, CompleteNoFiles, "--")
+	complete([]string{"prod\tproduction cluster"}, CompleteNoFiles, "--context", "p")
+	complete([]string{"--context=dev"}, CompleteNoFiles, "--context=d")
+	complete([]string{"yaml", "yml"}, CompleteFiles, "-n", "x", "-f", "")
+	complete([]string{}, CompleteDirs, "--dir", "")
+	complete([]string{}, CompleteFiles, "--namespace", "")
+	complete([]string{"--follow", "-n\tnamespace", "--namespace\tnamespace"}, CompleteNoFiles, "logs", "-")
+	complete([]string{"web-foo", "db-foo"}, CompleteNoSpace|CompleteNoFiles, "-n", "foo", "logs", "--follow", "")
+	complete([]string{"db-foo"}, CompleteNoSpace|CompleteNoFiles, "logs", "--unknown", "-n", "foo", "d")
+	complete([]string{}, CompleteNoFiles, "logs", "pod", "")
+
+	parser.AddCompletionSubParser()
+	inv, err := parser.ParseInvocation("__complete", "-n", "--context", "")
+	assertError(t, false, err)
+	assertSliceEqual(t, []string{"-n", "--context", ""}, inv.Leaf().Args)
+	assertEqual(t, true, inv.Leaf().Parser.Hidden)
+}
+
+func TestCompletionScriptsWithoutComplete(t *testing.T) {
+	// without the "__complete" subparser completers are ignored
+	parser := dynamicTestParser()
+	scripts := map[string]func(io.Writer) error{
+		"bash":       parser.BashCompletion,
+		"zsh":        parser.ZshCompletion,
+		"fish":       parser.FishCompletion,
+		"powershell": parser.PowerShellCompletion,
+	}
+
+	for name, script := range scripts {
+		b := &bytes.Buffer{}
+		assertError(t, false, script(b))
+		if strings.Contains(b.String(), "__complete") || strings.Contains(b.String(), "_dynamic") {
+			t.Errorf("%s script uses the dynamic completion:\n%s", name, b)
+		}
+	}
+
+	b := &bytes.Buffer{}
+	assertError(t, false, parser.BashCompletion(b))
+	assertEqual(t, true, strings.Contains(b.String(), "\t'kube --context') COMPREPLY=($(compgen -f -- \"$cur\")); return ;;\n"))
+}
+
+func TestDynamicCompletionScripts(t *testing.T) {
+	parser := dynamicTestParser()
+	parser.AddCompletionSubParser()
+	scripts := map[string]func(io.Writer) error{
+		"dynamic.bash": parser.BashCompletion,
+		"dynamic.zsh":  parser.ZshCompletion,
+		"dynamic.fish": parser.FishCompletion,
+		"dynamic.ps1":  parser.PowerShellCompletion,
+	}
+
+	for name, script := range scripts {
+		b := &bytes.Buffer{}
+		assertError(t, false, script(b))
+		assertGolden(t, name, b.Bytes())
+	}
+
+	if _, err := exec.LookPath("bash"); err != nil {
+		t.Skip("bash not found")
+	}
+
+	b := &bytes.Buffer{}
+	assertError(t, false, parser.BashCompletion(b))
+
+	// fake program answering __complete
+	dir := t.TempDir()
+	prog := filepath.Join(dir, "kube")
+	fake := "#!/bin/sh\n[ \"$1\" = __complete ] || exit 1\nshift\nprintf '%s|' \"$@\" > \"$(dirname \"$0\")/args\"\nprintf 'prod\\tproduction cluster\\ndev\\n:2\\n'\n"
+	if err := os.WriteFile(prog, []byte(fake), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	script := b.String() + `
+COMP_WORDS=("$@")
+COMP_CWORD=$(($# - 1))
+_kube
+printf '%s\n' "${COMPREPLY[@]}"
+`
+	out, err := exec.Command("bash", "-c", script, "bash", prog, "-n", "x", "--context", "").Output()
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertSliceEqual(t, []string{"prod", "dev"}, strings.Fields(string(out)))
+
+	args, err := os.ReadFile(filepath.Join(dir, "args"))
+	assertError(t, false, err)
+	assertEqual(t, "-n|x|--context||", string(args))
+}
@@ -0,0 +1,175 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+func (a *ArgParser) ZshCompletion(w io.Writer) error {
+	nodes := a.completionNodes()
+	prog := nodes[0].key()
+	fn := "_" + shellIdentifier(prog)
+
+	dynamic := ""
+	if a.dynamicCompletion() {
+		dynamic = fn + "_dynamic"
+	}
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, "#compdef %s\n\n", prog)
+	if len(dynamic) > 0 {
+		fmt.Fprintf(b, zshDynamic, dynamic)
+	}
+	fmt.Fprintf(b, "%s() {\n", fn)
+	b.WriteString("\tlocal cmd i start=2\n")
+	if len(dynamic) > 0 {
+		b.WriteString("\tlocal -a orig_words=(\"${words[@]}\")\n")
+		b.WriteString("\tlocal orig_current=$CURRENT\n")
+	}
+	fmt.Fprintf(b, "\tcmd=%s\n\n", shellQuote(prog))
+
+	// find the selected parser, skipping option arguments
+	b.WriteString("\tfor ((i = 2; i < CURRENT; i++)); do\n")
+	b.WriteString("\t\tcase \"$cmd ${words[i]}\" in\n")
+	for _, node := range nodes {
+		for _, name := range node.parser.subParserNames(true) {
+			sub := node.parser.subparsers[name]
+			patterns := make([]string, 0)
+			for _, alias := range append([]string{name}, sub.subaliases...) {
+				patterns = append(patterns, shellQuote(node.key()+" "+alias))
+			}
+			fmt.Fprintf(b, "\t\t%s) cmd=%s; start=$((i + 1)) ;;\n", strings.Join(patterns, "|"), shellQuote(node.key()+" "+name))
+		}
+		for _, alias := range node.parser.completionOptions() {
+			if alias[0].Nargs == 0 {
+				continue
+			}
+			fmt.Fprintf(b, "\t\t%s) ((i += %d)) ;;\n", bashPatterns(node, alias), alias[0].Nargs)
+		}
+	}
+	b.WriteString("\t\tesac\n")
+	b.WriteString("\tdone\n\n")
+
+	// only show the arguments of the selected parser to _arguments
+	b.WriteString("\twords=(\"${words[1]}\" \"${(@)words[start,-1]}\")\n")
+	b.WriteString("\t((CURRENT -= start - 2))\n\n")
+
+	b.WriteString("\tcase \"$cmd\" in\n")
+	for _, node := range nodes {
+		fmt.Fprintf(b, "\t%s)\n", shellQuote(node.key()))
+		b.WriteString("\t\t_arguments -s")
+		for _, spec := range zshSpecs(node.parser, dynamic) {
+			b.WriteString(" \\\n\t\t\t" + shellQuote(spec))
+		}
+		b.WriteString("\n\t\t;;\n")
+	}
+	b.WriteString("\tesac\n")
+	b.WriteString("}\n\n")
+	fmt.Fprintf(b, "%s \"$@\"\n", fn)
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// return _arguments specs of a parser. dynamic is the function completing
+// options with a Completer
+func zshSpecs(a *ArgParser, dynamic string) []string {
+	specs := make([]string, 0)
+
+	for _, alias := range a.completionOptions() {
+		names := make([]string, 0, len(alias))
+		for _, opt := range alias {
+			names = append(names, opt.String())
+		}
+
+		base := alias[0]
+		args := ""
+		for i := 0; i < base.Nargs; i++ {
+			args += ":" + zshEscape(a.metavar(base)) + ":" + zshAction(base, dynamic)
+		}
+
+		for _, opt := range alias {
+			spec := opt.String()
+			if len(base.Description) > 0 {
+				spec += "[" + zshEscape(flatten(base.Description)) + "]"
+			}
+			spec += args
+			if len(alias) > 1 {
+				spec = "(" + strings.Join(names, " ") + ")" + spec
+			}
+			specs = append(specs, spec)
+		}
+	}
+
+	for _, opt := range a.pos {
+		spec := ":" + zshEscape(a.metavar(opt)) + ":" + zshAction(opt, dynamic)
+		if opt.Nargs < 0 {
+			spec = "*" + spec
+		}
+		specs = append(specs, spec)
+	}
+
+	if subnames := a.subParserNames(false); len(subnames) > 0 {
+		items := make([]string, 0, len(subnames))
+		for _, name := range subnames {
+			desc := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(flatten(a.subparsers[name].Description))
+			items = append(items, zshEscape(name)+`\:"`+desc+`"`)
+		}
+		specs = append(specs, ":command:(("+strings.Join(items, " ")+"))")
+	}
+
+	return specs
+}
+
+func zshAction(opt *Option, dynamic string) string {
+	if opt.Completer != nil && len(dynamic) > 0 {
+		return dynamic
+	}
+	if len(opt.Choices) == 0 {
+		return "_files"
+	}
+	choices := make([]string, 0, len(opt.Choices))
+	for _, choice := range opt.Choices {
+		choices = append(choices, zshEscape(choice))
+	}
+	return "(" + strings.Join(choices, " ") + ")"
+}
+
+// completes using the "__complete" subparser, with the words saved by
+// the main function before narrowing them to the selected parser
+const zshDynamic = `%s() {
+	local out directive
+	local -a lines candidates
+	out=$("${orig_words[1]}" __complete "${(@)orig_words[2,orig_current]}" 2>/dev/null) || return
+	lines=("${(@f)out}")
+	directive=${lines[-1]#:}
+	lines=("${(@)lines[1,-2]}")
+	if ((directive & 4)); then
+		if ((${#lines})); then
+			_files -g "*.(${(j:|:)lines})"
+		else
+			_files
+		fi
+	elif ((directive & 8)); then
+		_files -/
+	else
+		candidates=("${(@)${(@)lines//:/\\:}//$'\t'/:}")
+		if ((directive & 1)); then
+			_describe 'values' candidates -S ''
+		else
+			_describe 'values' candidates
+		fi
+	fi
+}
+
+`
+
+func zshEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `[`, `\[`, `]`, `\]`, `:`, `\:`).Replace(s)
+}
+
+// return s as a single line
+func flatten(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -7,6 +7,7 @@ import (
 
 type Context struct {
 	parser *ArgParser
+	inv    *Invocation
 
 	// positional index
 	pindex int
@@ -17,6 +18,10 @@ type Context struct {
 	args  []string
 	abort bool
 	err   error
+
+	// set by completers
+	directive  CompletionDirective
+	extensions []string
 }
 
 func (c *Context) Abort() {
@@ -67,37 +72,70 @@ func (c *Context) parse() error {
 			break
 		}
 
+		if prefix, lines, ok := c.parser.responseFiles(); ok && strings.HasPrefix(c.args[0], prefix) && len(c.args[0]) > len(prefix) {
+			args, err := readResponseFile(c.args[0][len(prefix):], prefix, lines, nil)
+			if err != nil {
+				if c.inv.completion != nil {
+					c.Skip(1)
+					continue
+				}
+				return err
+			}
+			c.args = append(args, c.args[1:]...)
+			continue
+		}
+
 		if tmp := c.expand(c.args[0]); len(tmp) > 1 {
 			c.args = append(tmp, c.args[1:]...)
 		}
 
 		opt, err := c.getOption(c.Peek())
 		if err != nil {
+			if path := c.parser.findPlugin(c.Peek()); len(path) > 0 {
+				c.inv.PluginName = c.Next()
+				c.inv.Plugin = path
+				c.inv.PluginArgs = c.Remain()
+				c.Abort()
+				return nil
+			}
+			if c.inv.completion != nil && c.parser.defaultSubParser() == nil {
+				c.Skip(1)
+				continue
+			}
 			if c.parser.unparceable != nil {
 				c.parser.unparceable(c, c.Peek(), err)
 				c.Skip(1)
 				continue
 			}
+			if sub := c.parser.defaultSubParser(); sub != nil {
+				return c.selectSubParser(sub)
+			}
 			return err
 		}
 
 		if opt == nil {
-			c.parser.SubParserName = c.Peek()
-			c.parser.SubParser = c.parser.subparsers[c.Peek()]
-			c.Skip(1)
-			return c.parser.SubParser.Parse(c.Remain()...)
+			return c.selectSubParser(c.parser.subparsers[c.Next()])
 		}
 
 		if !opt.Positional {
 			c.Skip(1)
 		}
 
+		if c.inv.completion == nil {
+			opt = c.deprecated(opt)
+		}
+
 		nargs := opt.Nargs
 		if nargs < 0 {
 			nargs = 1
 		}
 
 		if c.Remaining() < nargs {
+			if c.inv.completion != nil {
+				c.inv.completion.pending = opt
+				c.Skip(c.Remaining())
+				break
+			}
 			var suffix string
 			if nargs == 1 {
 				suffix = "an argument"
@@ -107,14 +145,20 @@ func (c *Context) parse() error {
 			return fmt.Errorf("option %q requires %s", opt.String(), suffix)
 		}
 
-		if opt.Callback != nil {
-			c.opt = opt
-			opt.Callback(c, c.NextN(nargs)...)
-			c.opt = nil
-		} else {
-			c.Skip(nargs)
+		args := c.NextN(nargs)
+		if c.inv.completion != nil {
+			c.owner(opt).setOption(opt, args...)
+			continue
+		}
+
+		if err := c.readSecret(opt, args); err != nil {
+			return err
 		}
-		opt.set = true
+
+		if err := c.call(opt, args...); err != nil {
+			return err
+		}
+		c.owner(opt).setOption(opt, args...)
 
 		if c.err != nil {
 			break
@@ -123,18 +167,43 @@ func (c *Context) parse() error {
 	return c.err
 }
 
+// validates args and passes them to the option callback
+func (c *Context) call(opt *Option, args ...string) error {
+	for _, arg := range args {
+		if !opt.isChoice(arg) {
+			return fmt.Errorf("option %s is invalid, choose from: %s", optionValue(opt, arg), strings.Join(opt.Choices, ", "))
+		}
+	}
+
+	if opt.Callback != nil {
+		c.opt = opt
+		opt.Callback(c, args...)
+		c.opt = nil
+	}
+
+	return nil
+}
+
+// parses the remaining arguments with sub
+func (c *Context) selectSubParser(sub *ArgParser) error {
+	c.inv.SubParserName = sub.subname
+	c.inv.SubParser = sub
+	c.inv.Sub = newInvocation(sub, c.inv)
+	return sub.parse(c.inv.Sub, c.Remain()...)
+}
+
 func (c *Context) getOption(val string) (*Option, error) {
 	if strings.HasPrefix(val, "--") && len(val) > 2 {
 		optname := val[2:]
-		opt, ok := c.parser.opts[optname]
-		if !ok || len(opt.Name) == 1 {
+		opt, _ := c.parser.findOption(optname)
+		if opt == nil || len(opt.Name) == 1 {
 			return nil, fmt.Errorf("unknown option %q", optname)
 		}
 		return opt, nil
 	} else if strings.HasPrefix(val, "-") && len(val) > 1 {
 		optname := val[1:]
-		opt, ok := c.parser.opts[optname]
-		if !ok {
+		opt, _ := c.parser.findOption(optname)
+		if opt == nil {
 			return nil, fmt.Errorf("unknown option %q", optname)
 		}
 		return opt, nil
@@ -169,11 +238,56 @@ func (c *Context) Remaining() int {
 	return len(c.args)
 }
 
+// return the invocation of the parser that declared opt, which differs
+// from the current one for persistent options
+func (c *Context) owner(opt *Option) *Invocation {
+	if opt.Positional {
+		return c.inv
+	}
+	_, parser := c.parser.findOption(opt.Name)
+	for inv := c.inv; inv != nil; inv = inv.Parent {
+		if inv.Parser == parser {
+			return inv
+		}
+	}
+	return c.inv
+}
+
+// warns about opt if it is deprecated and return its replacement, if any
+func (c *Context) deprecated(opt *Option) *Option {
+	base, owner := opt, c.parser
+	if !opt.Positional {
+		_, owner = c.parser.findOption(opt.Name)
+		base = owner.base(opt)
+	}
+
+	if len(base.Deprecated) > 0 {
+		root := c.inv.Root()
+		if !root.warned[base] {
+			root.warned[base] = true
+			w := c.inv.Stderr()
+			style := c.parser.theme().styler(c.parser.colorize(w))
+			fmt.Fprintf(w, "%s option %s is deprecated: %s\n", style("warning", "warning:"), opt.String(), base.Deprecated)
+		}
+	}
+
+	if len(base.Replacement) == 0 {
+		return opt
+	}
+	// checked when opt was added
+	return owner.opts[base.Replacement]
+}
+
 // return current option
 func (c *Context) Option() *Option {
 	return c.opt
 }
 
+// return the state of the parse in progress
+func (c *Context) Invocation() *Invocation {
+	return c.inv
+}
+
 func (c *Context) expand(val string) []string {
 	r := make([]string, 0)
 
@@ -185,8 +299,8 @@ func (c *Context) expand(val string) []string {
 		for i := 1; i < len(val); i++ {
 			optname := val[i : i+1]
 			r = append(r, "-"+optname)
-			opt, ok := c.parser.opts[optname]
-			if ((ok && opt.Nargs > 0) || optname == "-") && i != len(val)-1 {
+			opt, _ := c.parser.findOption(optname)
+			if ((opt != nil && opt.Nargs > 0) || optname == "-") && i != len(val)-1 {
 				r = append(r, val[i+1:])
 				return r
 			}
@@ -0,0 +1,265 @@
+package argparse
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// data of a generated documentation page
+type docPage struct {
+	*Help
+	Title string
+	Usage string
+	// empty for the root parser
+	ParentTitle string
+	ParentLink  string
+	Commands    []docCommand
+}
+
+type docCommand struct {
+	HelpCommand
+	// empty for plugins
+	Link string
+}
+
+func (a *ArgParser) docPage(ext string) *docPage {
+	h := a.Help()
+	title := strings.Join(a.commandPath(), " ")
+	p := &docPage{
+		Help:     h,
+		Title:    title,
+		Usage:    strings.TrimSpace(title + " " + strings.Join(h.UsageArgs, " ")),
+		Commands: make([]docCommand, 0, len(h.Commands)),
+	}
+	if a.parent != nil {
+		p.ParentTitle = strings.Join(a.parent.commandPath(), " ")
+		p.ParentLink = a.parent.manName() + ext
+	}
+	for _, cmd := range h.Commands {
+		c := docCommand{HelpCommand: cmd}
+		if cmd.Parser != nil {
+			c.Link = cmd.Parser.manName() + ext
+		}
+		p.Commands = append(p.Commands, c)
+	}
+	return p
+}
+
+// writes the markdown reference page of a
+func (a *ArgParser) Markdown(w io.Writer) error {
+	p := a.docPage(".md")
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, "# %s\n\n", markdownText(p.Title))
+	if len(p.ParentLink) > 0 {
+		fmt.Fprintf(b, "Parent: [%s](%s)\n\n", markdownText(p.ParentTitle), p.ParentLink)
+	}
+	if len(p.Description) > 0 {
+		b.WriteString(markdownText(strings.TrimSpace(p.Description)) + "\n\n")
+	}
+
+	b.WriteString("## Usage\n\n")
+	b.WriteString("```\n" + p.Usage + "\n```\n")
+
+	for _, group := range p.Groups {
+		fmt.Fprintf(b, "\n## %s\n\n", markdownText(capitalize(group.Title)))
+		if len(group.Description) > 0 {
+			b.WriteString(markdownText(strings.TrimSpace(group.Description)) + "\n\n")
+		}
+		b.WriteString("| Option | Metavar | Default | Env | Required | Description |\n")
+		b.WriteString("| --- | --- | --- | --- | --- | --- |\n")
+		for _, opt := range group.Options {
+			names := make([]string, 0, len(opt.Names))
+			for _, name := range opt.Names {
+				names = append(names, markdownCode(name))
+			}
+			required := "no"
+			if opt.Required {
+				required = "yes"
+			}
+			fmt.Fprintf(b, "| %s | %s | %s | %s | %s | %s |\n",
+				strings.Join(names, ", "), markdownCode(opt.Metavar), markdownCode(opt.Default),
+				markdownCode(opt.Env), required, markdownCell(opt.Description))
+		}
+	}
+
+	if len(p.Commands) > 0 {
+		b.WriteString("\n## Commands\n\n")
+		b.WriteString("| Command | Aliases | Description |\n")
+		b.WriteString("| --- | --- | --- |\n")
+		for _, cmd := range p.Commands {
+			name := markdownCell(cmd.Name)
+			if len(cmd.Link) > 0 {
+				name = "[" + name + "](" + cmd.Link + ")"
+			}
+			fmt.Fprintf(b, "| %s | %s | %s |\n", name, markdownCell(strings.Join(cmd.Aliases, ", ")), markdownCell(cmd.Description))
+		}
+	}
+
+	if len(p.Examples) > 0 {
+		b.WriteString("\n## Examples\n")
+		for _, example := range p.Examples {
+			b.WriteString("\n```\n" + example.Command + "\n```\n")
+			if len(example.Description) > 0 {
+				b.WriteString("\n" + markdownText(strings.TrimSpace(example.Description)) + "\n")
+			}
+		}
+	}
+
+	if len(p.Epilog) > 0 {
+		b.WriteString("\n" + markdownText(strings.TrimSpace(p.Epilog)) + "\n")
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writes the html reference page of a
+func (a *ArgParser) HTML(w io.Writer) error {
+	return htmlTemplate.Execute(w, a.docPage(".html"))
+}
+
+// writes the markdown page of a and every visible subparser to dir, named
+// after the command path, e.g. "tool-remote-add.md"
+func (a *ArgParser) WriteMarkdown(dir string) error {
+	return a.writeDocs(dir, ".md", (*ArgParser).Markdown)
+}
+
+// writes the html page of a and every visible subparser to dir, named
+// after the command path, e.g. "tool-remote-add.html"
+func (a *ArgParser) WriteHTML(dir string) error {
+	return a.writeDocs(dir, ".html", (*ArgParser).HTML)
+}
+
+func (a *ArgParser) writeDocs(dir, ext string, write func(a *ArgParser, w io.Writer) error) error {
+	f, err := os.Create(filepath.Join(dir, a.manName()+ext))
+	if err != nil {
+		return err
+	}
+	if err := write(a, f); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	for _, subname := range a.subParserNames(false) {
+		if err := a.subparsers[subname].writeDocs(dir, ext, write); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// return s with the characters markdown would interpret escaped. list
+// markers are kept so lists in descriptions stay lists
+func markdownText(s string) string {
+	b := &strings.Builder{}
+	for i, line := range strings.Split(s, "\n") {
+		if i > 0 {
+			b.WriteRune('\n')
+		}
+		if trimmed := strings.TrimLeft(line, " "); strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, ">") {
+			b.WriteString(line[:len(line)-len(trimmed)] + `\`)
+			line = trimmed
+		}
+		for _, r := range line {
+			if strings.ContainsRune("\\`*_[]<>", r) {
+				b.WriteRune('\\')
+			}
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(markdownText(flatten(s)), "|", `\|`)
+}
+
+func markdownCode(s string) string {
+	if len(s) == 0 {
+		return ""
+	}
+	return "`" + strings.ReplaceAll(flatten(s), "|", `\|`) + "`"
+}
+
+// return s with its first letter in upper case
+func capitalize(s string) string {
+	if len(s) == 0 {
+		return s
+	}
+	r, size := utf8.DecodeRuneInString(s)
+	return string(unicode.ToUpper(r)) + s[size:]
+}
+
+var htmlTemplate = template.Must(template.New("page").Funcs(template.FuncMap{
+	"title": capitalize,
+	"join":  strings.Join,
+	"paragraphs": func(s string) []string {
+		r := make([]string, 0)
+		for _, paragraph := range strings.Split(strings.TrimSpace(s), "\n\n") {
+			if paragraph = flatten(paragraph); len(paragraph) > 0 {
+				r = append(r, paragraph)
+			}
+		}
+		return r
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{- if .ParentLink}}
+<p>Parent: <a href="{{.ParentLink}}">{{.ParentTitle}}</a></p>
+{{- end}}
+{{- range paragraphs .Description}}
+<p>{{.}}</p>
+{{- end}}
+<h2>Usage</h2>
+<pre><code>{{.Usage}}</code></pre>
+{{- range .Groups}}
+<h2>{{title .Title}}</h2>
+{{- range paragraphs .Description}}
+<p>{{.}}</p>
+{{- end}}
+<table>
+<tr><th>Option</th><th>Metavar</th><th>Default</th><th>Env</th><th>Required</th><th>Description</th></tr>
+{{- range .Options}}
+<tr><td>{{range $i, $name := .Names}}{{if $i}}, {{end}}<code>{{$name}}</code>{{end}}</td><td>{{with .Metavar}}<code>{{.}}</code>{{end}}</td><td>{{with .Default}}<code>{{.}}</code>{{end}}</td><td>{{with .Env}}<code>{{.}}</code>{{end}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Commands}}
+<h2>Commands</h2>
+<table>
+<tr><th>Command</th><th>Aliases</th><th>Description</th></tr>
+{{- range .Commands}}
+<tr><td>{{if .Link}}<a href="{{.Link}}">{{.Name}}</a>{{else}}{{.Name}}{{end}}</td><td>{{join .Aliases ", "}}</td><td>{{.Description}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Examples}}
+<h2>Examples</h2>
+{{- range .Examples}}
+<pre><code>{{.Command}}</code></pre>
+{{- range paragraphs .Description}}
+<p>{{.}}</p>
+{{- end}}
+{{- end}}
+{{- end}}
+{{- range paragraphs .Epilog}}
+<p>{{.}}</p>
+{{- end}}
+</body>
+</html>
+`))
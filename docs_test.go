@@ -0,0 +1,40 @@
+package argparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDocs(t *testing.T) {
+	parser := toolTestParser()
+	parser.Description = "tool manages remotes. it also\nshows logs | more.\n\nsecond <paragraph>"
+	parser.AddExample("tool remote add origin", "adds a remote named origin")
+	parser.AddExample("tool log", "")
+	parser.Epilog = "report bugs to <bugs@example.com>"
+	parser.AddOption(Option{Name: "depth", Nargs: 1, Default: "1", Env: "TOOL_DEPTH", Description: "history depth"})
+	parser.AddGroup("écran", "# not a *heading*").AddOption(Option{Name: "width", Nargs: 1, Description: "width in <columns>, e.g. 80"})
+
+	for _, ext := range []string{".md", ".html"} {
+		dir := t.TempDir()
+		if ext == ".md" {
+			assertError(t, false, parser.WriteMarkdown(dir))
+		} else {
+			assertError(t, false, parser.WriteHTML(dir))
+		}
+
+		entries, err := os.ReadDir(dir)
+		assertError(t, false, err)
+		names := make([]string, 0)
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		assertSliceEqual(t, []string{"tool-log" + ext, "tool-remote-add" + ext, "tool-remote" + ext, "tool" + ext}, names)
+
+		for _, name := range []string{"tool" + ext, "tool-remote-add" + ext} {
+			page, err := os.ReadFile(filepath.Join(dir, name))
+			assertError(t, false, err)
+			assertGolden(t, name, page)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/sloweax/argparse"
+)
+
+// $ go run . -v remote add origin
+// verbose
+// adding remote origin
+
+// $ go run . remote
+// no handler for "./execute remote", expected a command: add, del
+
+func main() {
+	parser := argparse.NewWithDefaults()
+	remote := argparse.NewWithDefaults()
+	add := argparse.NewWithDefaults()
+	del := argparse.NewWithDefaults()
+
+	parser.AddSubParser("remote", remote)
+	remote.AddSubParser("add", add)
+	remote.AddSubParser("del", del)
+
+	parser.AddOption(argparse.Option{Name: "v"})
+	parser.PreRun = func(ctx context.Context, inv *argparse.Invocation) error {
+		if inv.IsSet("v") {
+			fmt.Println("verbose")
+		}
+		return nil
+	}
+
+	add.AddOption(argparse.Option{Name: "name", Positional: true, Nargs: 1, Required: true})
+	add.Run = func(ctx context.Context, inv *argparse.Invocation) error {
+		fmt.Printf("adding remote %s\n", inv.Value("name"))
+		return nil
+	}
+
+	del.AddOption(argparse.Option{Name: "name", Positional: true, Nargs: 1, Required: true})
+	del.Run = func(ctx context.Context, inv *argparse.Invocation) error {
+		fmt.Printf("deleting remote %s\n", inv.Value("name"))
+		return nil
+	}
+
+	if err := parser.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
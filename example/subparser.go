@@ -13,6 +13,9 @@ import (
 // $ go run . --prefix very- del bad-file
 // deleting file very-bad-file
 
+// $ go run . --prefix very-
+// a command is required: add, del
+
 func main() {
 	parser := argparse.NewWithDefaults()
 	add_parser := argparse.NewWithDefaults()
@@ -20,6 +23,7 @@ func main() {
 
 	parser.AddSubParser("add", add_parser)
 	parser.AddSubParser("del", del_parser)
+	parser.SubParserRequired = true
 
 	prefix := ""
 	parser.AddOption(argparse.String("prefix", &prefix))
@@ -28,17 +32,16 @@ func main() {
 	add_parser.AddOption(argparse.StringPositional("file", &file).SetRequired(true))
 	del_parser.AddOption(argparse.StringPositional("file", &file).SetRequired(true))
 
-	if err := parser.ParseArgs(); err != nil {
+	inv, err := parser.ParseArgsInvocation()
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 
-	switch parser.SubParser {
+	switch inv.SubParser {
 	case add_parser:
 		fmt.Printf("adding file %s\n", prefix+file)
 	case del_parser:
 		fmt.Printf("deleting file %s\n", prefix+file)
-	default:
-		fmt.Println("nothing to do")
 	}
 }
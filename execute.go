@@ -0,0 +1,65 @@
+package argparse
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// parses os.Args and dispatches to the selected parser handler
+func (a *ArgParser) Execute() error {
+	return a.ExecuteContext(context.Background(), os.Args[1:]...)
+}
+
+// parses args, calls PreRun on every selected parser from the root down,
+// Run on the innermost selected parser (or executes the selected plugin)
+// and PostRun from the innermost up
+func (a *ArgParser) ExecuteContext(ctx context.Context, args ...string) error {
+	inv, err := a.ParseInvocation(args...)
+	if err != nil {
+		return err
+	}
+	return inv.Execute(ctx)
+}
+
+// dispatches an already parsed invocation. see ExecuteContext
+func (i *Invocation) Execute(ctx context.Context) error {
+	root := i.Root()
+	leaf := root.Leaf()
+
+	if leaf.Parser.Run == nil && len(leaf.Plugin) == 0 {
+		if len(leaf.Parser.subparsers) > 0 {
+			return fmt.Errorf("no handler for %q, expected a command: %s", leaf.Parser.Name, strings.Join(leaf.Parser.subParserNames(false), ", "))
+		}
+		return fmt.Errorf("no handler for %q", leaf.Parser.Name)
+	}
+
+	for inv := root; inv != nil; inv = inv.Sub {
+		if inv.Parser.PreRun == nil {
+			continue
+		}
+		if err := inv.Parser.PreRun(ctx, inv); err != nil {
+			return err
+		}
+	}
+
+	if len(leaf.Plugin) > 0 {
+		if err := leaf.RunPlugin(ctx); err != nil {
+			return err
+		}
+	} else if err := leaf.Parser.Run(ctx, leaf); err != nil {
+		return err
+	}
+
+	for inv := leaf; inv != nil; inv = inv.Parent {
+		if inv.Parser.PostRun == nil {
+			continue
+		}
+		if err := inv.Parser.PostRun(ctx, inv); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
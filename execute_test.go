@@ -0,0 +1,54 @@
+package argparse
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestExecute(t *testing.T) {
+	calls := make([]string, 0)
+	hook := func(name string) func(context.Context, *Invocation) error {
+		return func(ctx context.Context, inv *Invocation) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	parser := New()
+	parser.Name = "git"
+	parser.PreRun = hook("root pre")
+	parser.PostRun = hook("root post")
+
+	remote := New()
+	remote.PreRun = hook("remote pre")
+	parser.AddSubParser("remote", remote)
+
+	add := New()
+	add.AddOption(Option{Name: "url", Positional: true, Nargs: 1})
+	add.PostRun = hook("add post")
+	add.Run = func(ctx context.Context, inv *Invocation) error {
+		calls = append(calls, "add "+inv.Value("url"))
+		return nil
+	}
+	remote.AddSubParser("add", add)
+
+	assertError(t, false, parser.ExecuteContext(context.Background(), "remote", "add", "foo"))
+	assertSliceEqual(t, []string{"root pre", "remote pre", "add foo", "add post", "root post"}, calls)
+
+	calls = calls[:0]
+	err := parser.ExecuteContext(context.Background(), "remote")
+	assertError(t, true, err)
+	assertEqual(t, `no handler for "git remote", expected a command: add`, err.Error())
+	assertEqual(t, 0, len(calls))
+
+	fail := errors.New("fail")
+	remote.PreRun = func(ctx context.Context, inv *Invocation) error {
+		return fail
+	}
+	err = parser.ExecuteContext(context.Background(), "remote", "add", "foo")
+	if err != fail {
+		t.Errorf("expected PreRun error; got %v", err)
+	}
+	assertSliceEqual(t, []string{"root pre"}, calls)
+}
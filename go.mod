@@ -1,3 +1,7 @@
 module github.com/sloweax/argparse
 
 go 1.19
+
+require golang.org/x/term v0.29.0
+
+require golang.org/x/sys v0.30.0 // indirect
@@ -0,0 +1,41 @@
+package argparse
+
+// a named section of options in the usage
+type OptionGroup struct {
+	Title       string
+	Description string
+	// make every option of this group persistent, listing them under this
+	// group in the usage of subparsers
+	Persistent bool
+
+	parser *ArgParser
+}
+
+// return the group of a with title, adding it if there is none. groups are
+// shown in the order they were added
+func (a *ArgParser) AddGroup(title, description string) *OptionGroup {
+	if len(title) == 0 {
+		panic("cant have group without title")
+	}
+	for _, g := range a.groups {
+		if g.Title == title {
+			if len(g.Description) == 0 {
+				g.Description = description
+			}
+			return g
+		}
+	}
+	g := &OptionGroup{Title: title, Description: description, parser: a}
+	a.groups = append(a.groups, g)
+	return g
+}
+
+func (g *OptionGroup) AddOption(opt Option) {
+	opt.group = g
+	g.parser.AddOption(opt)
+}
+
+func (g *OptionGroup) AddOptionWithAlias(opt Option, aliases ...string) {
+	opt.group = g
+	g.parser.AddOptionWithAlias(opt, aliases...)
+}
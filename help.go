@@ -0,0 +1,434 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// data shown by Usage and generated documentation
+type Help struct {
+	Parser      *ArgParser
+	Name        string
+	Description string
+	Examples    []Example
+	Epilog      string
+	// arguments of the usage line
+	UsageArgs []string
+	// "arguments", "options", groups added with AddGroup and
+	// "global options", skipping empty ones
+	Groups   []HelpGroup
+	Commands []HelpCommand
+}
+
+// a command line and its explanation
+type Example struct {
+	Command     string
+	Description string
+}
+
+// adds an example shown in the usage
+func (a *ArgParser) AddExample(command, description string) {
+	a.Examples = append(a.Examples, Example{Command: command, Description: description})
+}
+
+type HelpGroup struct {
+	Title       string
+	Description string
+	Options     []HelpOption
+}
+
+// an option along with its aliases
+type HelpOption struct {
+	Option *Option
+	// option strings, e.g. "-v" and "--verbose"
+	Names []string
+	// empty if the option takes no arguments. the Metavar of the parser if
+	// the option has none, see Option.Metavar
+	Metavar     string
+	Description string
+	Default     string
+	Env         string
+	Required    bool
+	Positional  bool
+}
+
+type HelpCommand struct {
+	// nil for plugins
+	Parser      *ArgParser
+	Name        string
+	Aliases     []string
+	Description string
+}
+
+func (a *ArgParser) Help() *Help {
+	h := &Help{
+		Parser:      a,
+		Name:        a.Name,
+		Description: a.Description,
+		Examples:    a.Examples,
+		Epilog:      a.Epilog,
+		UsageArgs:   a.usageStrings(),
+		Groups:      make([]HelpGroup, 0),
+		Commands:    make([]HelpCommand, 0),
+	}
+
+	args := HelpGroup{Title: "arguments"}
+	opts := HelpGroup{Title: "options"}
+	globals := HelpGroup{Title: "global options"}
+	groups := make([]*HelpGroup, 0)
+	index := map[*OptionGroup]*HelpGroup{}
+
+	// groups of a come first, in the order they were added. inherited
+	// groups follow in the order their options are found
+	for _, g := range a.groups {
+		index[g] = &HelpGroup{Title: g.Title, Description: g.Description}
+		groups = append(groups, index[g])
+	}
+	add := func(fallback *HelpGroup, aliases [][]*Option) {
+		for _, opt := range a.helpOptions(aliases) {
+			g := opt.Option.group
+			if g == nil {
+				fallback.Options = append(fallback.Options, opt)
+				continue
+			}
+			if _, ok := index[g]; !ok {
+				index[g] = &HelpGroup{Title: g.Title, Description: g.Description}
+				groups = append(groups, index[g])
+			}
+			index[g].Options = append(index[g].Options, opt)
+		}
+	}
+
+	own := make([][]*Option, 0)
+	for _, alias := range a.Aliases() {
+		if alias[0].Positional && alias[0].group == nil {
+			args.Options = append(args.Options, a.helpOptions([][]*Option{alias})...)
+		} else {
+			own = append(own, alias)
+		}
+	}
+	add(&opts, own)
+	add(&globals, a.globalAliases())
+
+	for _, group := range append(append([]*HelpGroup{&args, &opts}, groups...), &globals) {
+		if len(group.Options) > 0 {
+			h.Groups = append(h.Groups, *group)
+		}
+	}
+
+	for _, subname := range a.subParserNames(false) {
+		sub := a.subparsers[subname]
+		h.Commands = append(h.Commands, HelpCommand{Parser: sub, Name: subname, Aliases: sub.subaliases, Description: sub.Description})
+	}
+	for _, plugin := range a.pluginNames() {
+		h.Commands = append(h.Commands, HelpCommand{Name: plugin})
+	}
+
+	return h
+}
+
+func (a *ArgParser) helpOptions(aliases [][]*Option) []HelpOption {
+	opts := make([]HelpOption, 0, len(aliases))
+	for _, alias := range aliases {
+		base := alias[0]
+		if base.Hidden {
+			continue
+		}
+		opt := HelpOption{
+			Option:      base,
+			Names:       make([]string, 0, len(alias)),
+			Description: base.Description,
+			Default:     base.Default,
+			Env:         base.Env,
+			Required:    base.Required,
+			Positional:  base.Positional,
+		}
+		for _, o := range alias {
+			opt.Names = append(opt.Names, o.String())
+		}
+		if base.Secret && len(opt.Default) > 0 {
+			opt.Default = redacted
+		}
+		if base.Nargs > 0 && !base.Positional {
+			opt.Metavar = a.metavar(base)
+		}
+		opts = append(opts, opt)
+	}
+	return opts
+}
+
+// return names and metavar as shown in the usage
+func (o HelpOption) String() string {
+	str := strings.Join(o.Names, ", ")
+	if len(o.Metavar) > 0 {
+		str += " " + o.Metavar
+	}
+	return str
+}
+
+// return the description followed by the default value and environment
+// variable, if any
+func (o HelpOption) FullDescription() string {
+	extra := make([]string, 0)
+	if len(o.Default) > 0 {
+		extra = append(extra, "default: "+o.Default)
+	}
+	if len(o.Env) > 0 {
+		extra = append(extra, "env: "+o.Env)
+	}
+	if len(extra) == 0 {
+		return o.Description
+	}
+	tmp := "(" + strings.Join(extra, ", ") + ")"
+	if len(o.Description) == 0 {
+		return tmp
+	}
+	return o.Description + " " + tmp
+}
+
+// return name and aliases as shown in the usage
+func (c HelpCommand) String() string {
+	if len(c.Aliases) == 0 {
+		return c.Name
+	}
+	return c.Name + " (" + strings.Join(c.Aliases, ", ") + ")"
+}
+
+// template reproducing the default usage layout
+const DefaultHelpTemplate = `{{wrap (print (style "heading" "usage:") " " .Name " ") .UsageArgs}}
+{{- with .Description}}
+{{wrap "" .}}
+{{- end}}
+{{- $column := .Column "    " 5}}
+{{- range .Groups}}
+{{style "heading" (print .Title ":")}}
+{{with .Description}}{{wrap "    " .}}
+{{end}}
+{{- range .Options}}{{entry (print "    " (option .)) .FullDescription $column}}{{end}}
+{{- end}}
+{{- if .Commands}}
+{{style "heading" "commands:"}}
+{{range .Commands}}{{entry (print "    " (command .)) .Description $column}}{{end}}
+{{- end}}
+{{- if .Examples}}
+{{style "heading" "examples:"}}
+{{range .Examples}}{{print "    " .Command "\n"}}{{with .Description}}{{wrap "        " .}}{{end}}{{end}}
+{{- end}}
+{{- with .Epilog}}
+{{wrap "" .}}
+{{- end}}`
+
+// return the width of the longest option or command prefixed by indent,
+// plus gap. used to align descriptions
+func (h *Help) Column(indent string, gap int) int {
+	max := 0
+	for _, group := range h.Groups {
+		for _, opt := range group.Options {
+			if l := displayWidth(indent + opt.String()); l > max {
+				max = l
+			}
+		}
+	}
+	for _, cmd := range h.Commands {
+		if l := displayWidth(indent + cmd.String()); l > max {
+			max = l
+		}
+	}
+	return max + gap
+}
+
+// return the template used by a
+func (a *ArgParser) helpTemplate() string {
+	for p := a; p != nil; p = p.parent {
+		if len(p.HelpTemplate) > 0 {
+			return p.HelpTemplate
+		}
+	}
+	return DefaultHelpTemplate
+}
+
+// functions available to help templates:
+//
+//	wrap prefix text     text (a string or []string of words) after prefix,
+//	                     wrapped with continuation lines aligned to prefix.
+//	                     paragraphs and list items of strings are kept
+//	entry name text col  name followed by text starting at column col
+//	style part s         s styled with a part of the theme: "heading",
+//	                     "option", "metavar", "command" or "error"
+//	option o             styled names and metavar of a HelpOption
+//	command c            styled name and aliases of a HelpCommand
+//	width                the wrapping width of the parser
+//	join, upper, lower   strings.Join, strings.ToUpper, strings.ToLower
+func (a *ArgParser) helpFuncs(color bool) template.FuncMap {
+	width := a.width()
+	style := a.theme().styler(color)
+	return template.FuncMap{
+		"wrap": func(prefix string, text any) string {
+			return wrapText(prefix, text, width)
+		},
+		"entry": func(name string, text any, column int) string {
+			return entryText(name, text, column, width)
+		},
+		"style": style,
+		"option": func(o HelpOption) string {
+			names := make([]string, 0, len(o.Names))
+			for _, name := range o.Names {
+				names = append(names, style("option", name))
+			}
+			str := strings.Join(names, ", ")
+			if len(o.Metavar) > 0 {
+				str += " " + style("metavar", o.Metavar)
+			}
+			return str
+		},
+		"command": func(c HelpCommand) string {
+			str := style("command", c.Name)
+			if len(c.Aliases) > 0 {
+				str += " (" + strings.Join(c.Aliases, ", ") + ")"
+			}
+			return str
+		},
+		"width": func() int { return width },
+		"join":  strings.Join,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	}
+}
+
+// writes the help of a rendered with its template. it is colored if w is a
+// terminal, see Color
+func (a *ArgParser) WriteHelp(w io.Writer) error {
+	return a.writeHelp(w, a.colorize(w))
+}
+
+// nothing is written if the template fails
+func (a *ArgParser) writeHelp(w io.Writer, color bool) error {
+	t, err := template.New("help").Funcs(a.helpFuncs(color)).Parse(a.helpTemplate())
+	if err != nil {
+		return fmt.Errorf("help template: %w", err)
+	}
+	b := &strings.Builder{}
+	if err := t.Execute(b, a.Help()); err != nil {
+		return fmt.Errorf("help template: %w", err)
+	}
+	_, err = io.WriteString(w, b.String())
+	return err
+}
+
+// return the help of a, colored only if Color is ColorAlways. if its
+// template fails, the help is rendered with DefaultHelpTemplate instead
+func (a *ArgParser) Usage() string {
+	b := &strings.Builder{}
+	if err := a.WriteHelp(b); err != nil {
+		t := template.Must(template.New("help").Funcs(a.helpFuncs(a.colorize(b))).Parse(DefaultHelpTemplate))
+		t.Execute(b, a.Help())
+	}
+	return b.String()
+}
+
+func wrapText(prefix string, text any, width int) string {
+	if ws, ok := text.([]string); ok {
+		if len(ws) == 0 {
+			return strings.TrimSuffix(prefix, " ") + "\n"
+		}
+		return formatString(prefix, displayWidth(prefix), width, false, ws...)
+	}
+	return wrapParagraphs(prefix, displayWidth(prefix), width, fmt.Sprint(text))
+}
+
+func entryText(name string, text any, column, width int) string {
+	empty := len(strings.TrimSpace(fmt.Sprint(text))) == 0
+	if ws, ok := text.([]string); ok {
+		empty = len(ws) == 0
+	}
+	if empty {
+		return name + "\n"
+	}
+	if w := displayWidth(name); w < column {
+		name += strings.Repeat(" ", column-w)
+	}
+	return wrapText(name, text, width)
+}
+
+// adds -h, --help showing the usage of the selected parser and exiting
+func (a *ArgParser) addHelpOption() {
+	a.AddOptionWithAlias(Option{Name: "h", Description: "shows usage and exits", Callback: func(ctx *Context, args ...string) {
+		ctx.showHelp(ctx.parser)
+	}}, "help")
+	// list it before options added earlier
+	a.opts["h"].sort = -2
+	a.opts["help"].sort = -1
+	a.help = true
+}
+
+// writes the usage of p and exits, aborting the parse with ErrHelp
+func (c *Context) showHelp(p *ArgParser) {
+	if err := p.WriteHelp(c.inv.Stdout()); err != nil {
+		c.AbortWithError(err)
+		return
+	}
+	c.inv.doExit(0)
+	c.AbortWithError(ErrHelp)
+}
+
+// adds -h, --help to a and its subparsers, unless they already have it or
+// an option with the same name
+func (a *ArgParser) propagateHelp() {
+	if a.RawArgs {
+		return
+	}
+	if !a.help {
+		if _, ok := a.opts["h"]; ok {
+			return
+		}
+		if _, ok := a.opts["help"]; ok {
+			return
+		}
+		a.addHelpOption()
+	}
+	if a.DisableHelpPropagation {
+		return
+	}
+	for _, name := range a.subParserNames(true) {
+		a.subparsers[name].propagateHelp()
+	}
+}
+
+// return the subparser at path, a list of subparser names or aliases
+func (a *ArgParser) subParserAt(path ...string) (*ArgParser, error) {
+	p := a
+	for _, name := range path {
+		sub, ok := p.subparsers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown command %q", strings.Join(append(p.commandPath()[1:], name), " "))
+		}
+		p = sub
+	}
+	return p, nil
+}
+
+// adds a "help" subparser showing the usage of the command given by its
+// arguments, e.g. "help remote add", or of a if none is given. like -h,
+// --help it shows it while parsing and exits
+func (a *ArgParser) AddHelpSubParser() {
+	help := New()
+	help.Description = "shows usage of a command"
+	help.AddOption(Option{Name: "command", Positional: true, Nargs: -1, Completer: func(ctx *Context, prefix string) []string {
+		p, err := a.subParserAt(ctx.Invocation().Values("command")...)
+		if err != nil {
+			return nil
+		}
+		return p.subParserNames(false)
+	}})
+	help.parsed = func(ctx *Context) {
+		p, err := a.subParserAt(ctx.inv.Values("command")...)
+		if err != nil {
+			ctx.AbortWithError(err)
+			return
+		}
+		ctx.showHelp(p)
+	}
+	a.AddSubParser("help", help)
+}
@@ -0,0 +1,276 @@
+package argparse
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// parser tree rendered by the help, man page and docs tests
+func toolTestParser() *ArgParser {
+	parser := New()
+	parser.Name = "./tool"
+	parser.Width = 80
+	parser.AddOptionWithAlias(Option{Name: "v", Description: "verbose output", Persistent: true}, "verbose")
+	parser.AddOption(Option{Name: "color", Nargs: 1, Metavar: "when", Description: "colorize output", Choices: []string{"always", "never"}})
+	parser.AddOptionWithAlias(Option{Name: "c", Nargs: 1, Metavar: "file", Description: "config file"}, "config")
+
+	remote := New()
+	remote.Description = "manage remotes"
+	parser.AddSubParserWithAlias("remote", remote, "r")
+
+	add := New()
+	add.Description = "add a remote"
+	add.AddOption(Option{Name: "fetch", Description: "fetch after adding"})
+	add.AddOption(Option{Name: "name", Positional: true, Nargs: 1, Required: true})
+	remote.AddSubParser("add", add)
+
+	log := New()
+	log.Description = "show logs"
+	log.AddOption(Option{Name: "format", Nargs: 1, Choices: []string{"short", "full"}})
+	log.AddOption(Option{Name: "level", Positional: true, Nargs: 1, Choices: []string{"debug", "info"}})
+	parser.AddSubParser("log", log)
+
+	return parser
+}
+
+func TestHelpTemplate(t *testing.T) {
+	parser := toolTestParser()
+	parser.Name = "tool"
+	parser.Epilog = "see the manual for more"
+
+	assertEqual(t, "usage: tool [-v] [--color when] [-c file] <command> \n"+
+		"\noptions:\n"+
+		"    -v, --verbose         verbose output \n"+
+		"    --color when          colorize output \n"+
+		"    -c, --config file     config file \n"+
+		"\ncommands:\n"+
+		"    remote (r)            manage remotes \n"+
+		"    log                   show logs \n"+
+		"\nsee the manual for more \n", parser.Usage())
+
+	parser.HelpTemplate = `Usage: {{.Name}} {{join .UsageArgs " "}}
+{{range .Groups}}{{upper .Title}}
+{{range .Options}}{{entry (print "  " .) .Description 20}}{{end}}{{end}}`
+
+	remote := parser.subparsers["remote"]
+	remote.Name = "tool remote"
+	assertEqual(t, "Usage: tool remote <command>\n"+
+		"GLOBAL OPTIONS\n"+
+		"  -v, --verbose     verbose output \n", remote.Usage())
+
+	// an invalid template is reported instead of rendered
+	out := &bytes.Buffer{}
+	bad := NewWithDefaults()
+	bad.Name = "bad"
+	bad.Stdout = out
+	bad.Exit = func(code int) { t.Fatal("exited") }
+	bad.HelpTemplate = "{{.Missing}}"
+	err := bad.Parse("--help")
+	assertError(t, true, err)
+	assertEqual(t, false, errors.Is(err, ErrHelp))
+	assertEqual(t, true, strings.HasPrefix(err.Error(), "help template: "))
+	assertEqual(t, "", out.String())
+	assertEqual(t, true, strings.HasPrefix(bad.Usage(), "usage: bad [-h] \n"))
+}
+
+func TestHelpWidth(t *testing.T) {
+	parser := New()
+	parser.Name = "tool"
+	parser.Description = "first paragraph is long enough to wrap\n\nitems:\n- one\n- two is long enough to wrap it\n1. three"
+	parser.AddOption(Option{Name: "名前", Nargs: 1, Metavar: "値", Description: "wide characters"})
+	parser.AddOption(Option{Name: "n", Description: "narrow"})
+	sub := New()
+	sub.Name = "tool sub"
+	parser.AddSubParser("sub", sub)
+
+	parser.Width = 30
+	assertEqual(t, "usage: tool [--名前 値] [-n]\n"+
+		"            <command> \n"+
+		"\nfirst paragraph is long enough\n"+
+		"to wrap \n"+
+		"\nitems: \n"+
+		"- one \n"+
+		"- two is long enough to wrap\n"+
+		"  it \n"+
+		"1. three \n"+
+		"\noptions:\n"+
+		"    --名前 値     wide\n"+
+		"                  characters \n"+
+		"    -n            narrow \n"+
+		"\ncommands:\n"+
+		"    sub\n", parser.Usage())
+
+	assertEqual(t, 30, sub.width())
+	sub.Width = 50
+	assertEqual(t, 50, sub.width())
+
+	parser.Width = 0
+	t.Setenv("COLUMNS", "120")
+	assertEqual(t, 120, parser.width())
+	t.Setenv("COLUMNS", "")
+	assertEqual(t, BreakLineThreshold, parser.width())
+}
+
+func TestGroups(t *testing.T) {
+	type S struct {
+		Host    string `group:"networking" description:"server host"`
+		Port    int    `group:"networking"`
+		Verbose bool
+		File    string `type:"positional"`
+	}
+
+	s := S{}
+	parser := New()
+	parser.Width = 80
+	parser.Name = "tool"
+	parser.LoadStruct(&s)
+	net := parser.AddGroup("networking", "connection settings")
+	net.Persistent = true
+	net.AddOption(Option{Name: "timeout", Nargs: 1})
+	parser.AddGroup("output", "").AddOptionWithAlias(Option{Name: "q"}, "quiet")
+
+	assertEqual(t, "usage: tool [--host var] [--port var] [--verbose] [file] [--timeout var] [-q] \n"+
+		"\narguments:\n"+
+		"    file\n"+
+		"\noptions:\n"+
+		"    --verbose\n"+
+		"\nnetworking:\n"+
+		"    connection settings \n"+
+		"\n"+
+		"    --host var        server host \n"+
+		"    --port var\n"+
+		"    --timeout var\n"+
+		"\noutput:\n"+
+		"    -q, --quiet\n", parser.Usage())
+
+	sub := New()
+	sub.Name = "tool sub"
+	parser.AddSubParser("sub", sub)
+	assertEqual(t, "usage: tool sub\n"+
+		"\nnetworking:\n"+
+		"    connection settings \n"+
+		"\n"+
+		"    --host var        server host \n"+
+		"    --port var\n"+
+		"    --timeout var\n", sub.Usage())
+
+	assertError(t, false, parser.Parse("sub", "--port", "80"))
+	assertEqual(t, 80, s.Port)
+}
+
+func TestHelpSubParser(t *testing.T) {
+	stdout := &strings.Builder{}
+
+	parser := NewWithDefaults()
+	parser.Width = 80
+	parser.Name = "tool"
+	parser.Stdout = stdout
+	parser.Exit = func(code int) {}
+
+	remote := New()
+	add := New()
+	add.AddOption(Option{Name: "name", Positional: true, Nargs: 1, Required: true})
+	remote.AddSubParser("add", add)
+	parser.AddSubParserWithAlias("remote", remote, "r")
+
+	raw := New()
+	raw.DisableHelpPropagation = true
+	raw.AddSubParser("sub", New())
+	parser.AddSubParser("raw", raw)
+	parser.AddHelpSubParser()
+
+	assertEqual(t, "usage: tool remote add [-h] name \n"+
+		"\narguments:\n"+
+		"    name\n"+
+		"\noptions:\n"+
+		"    -h, --help     shows usage and exits \n", add.Usage())
+
+	// "help <path>" behaves like "<path> --help"
+	code := -1
+	parser.Exit = func(c int) { code = c }
+	err := parser.Parse("help", "r", "add")
+	if err != ErrHelp {
+		t.Errorf("expected ErrHelp; got %v", err)
+	}
+	assertEqual(t, add.Usage(), stdout.String())
+	assertEqual(t, 0, code)
+	parser.Exit = func(code int) {}
+
+	stdout.Reset()
+	err = parser.ExecuteContext(context.Background(), "help")
+	if err != ErrHelp {
+		t.Errorf("expected ErrHelp; got %v", err)
+	}
+	assertEqual(t, parser.Usage(), stdout.String())
+
+	stdout.Reset()
+	err = parser.Parse("remote", "add", "--help")
+	if err != ErrHelp {
+		t.Errorf("expected ErrHelp; got %v", err)
+	}
+	assertEqual(t, add.Usage(), stdout.String())
+
+	stdout.Reset()
+	err = parser.Parse("help", "remote", "nope")
+	assertError(t, true, err)
+	assertEqual(t, `unknown command "remote nope"`, err.Error())
+	assertEqual(t, "", stdout.String())
+
+	assertError(t, true, parser.Parse("raw", "sub", "--help"))
+	stdout.Reset()
+	err = parser.Parse("raw", "--help")
+	if err != ErrHelp {
+		t.Errorf("expected ErrHelp; got %v", err)
+	}
+
+	candidates, _ := parser.Complete("help", "remote", "")
+	assertSliceEqual(t, []string{"add"}, candidates)
+}
+
+func TestExamples(t *testing.T) {
+	type S struct {
+		_       struct{} `example:"tool -v add origin" explanation:"adds origin verbosely"`
+		_       struct{} `example:"tool list"`
+		_       struct{} `epilog:"report bugs to <bugs@example.com>"`
+		Verbose bool     `alias:"v"`
+	}
+
+	parser := New()
+	parser.Width = 80
+	parser.Name = "tool"
+	parser.LoadStruct(&S{})
+	parser.AddSubParser("add", New())
+
+	assertEqual(t, "usage: tool [--verbose] <command> \n"+
+		"\noptions:\n"+
+		"    --verbose, -v\n"+
+		"\ncommands:\n"+
+		"    add\n"+
+		"\nexamples:\n"+
+		"    tool -v add origin\n"+
+		"        adds origin verbosely \n"+
+		"    tool list\n"+
+		"\nreport bugs to <bugs@example.com> \n", parser.Usage())
+}
+
+func TestHelpMetavar(t *testing.T) {
+	parser := New()
+	parser.Name = "tool"
+	parser.Width = 80
+	parser.Metavar = "value"
+	parser.AddOption(Option{Name: "name", Nargs: 1})
+	parser.AddOption(Option{Name: "file", Nargs: 1, Metavar: "path"})
+	sub := New()
+	sub.AddOption(Option{Name: "depth", Nargs: 1})
+	parser.AddSubParser("sub", sub)
+
+	assertEqual(t, "usage: tool [--name value] [--file path] <command> \n", parser.String())
+	assertEqual(t, "usage: tool sub [--depth value] \n", sub.String())
+
+	// templates can tell a metavar was not set
+	parser.HelpTemplate = `{{range .Groups}}{{range .Options}}{{.Metavar}}={{.Option.Metavar}} {{end}}{{end}}`
+	assertEqual(t, "value= path=path ", parser.Usage())
+}
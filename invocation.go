@@ -0,0 +1,160 @@
+package argparse
+
+import "io"
+
+// per-parse state. a parser definition is never modified while parsing,
+// so the same *ArgParser can be parsed concurrently
+type Invocation struct {
+	Parser *ArgParser
+
+	// selected subparser
+	SubParser     *ArgParser
+	SubParserName string
+
+	// selected plugin executable, its command name and arguments
+	Plugin     string
+	PluginName string
+	PluginArgs []string
+
+	// arguments given to a parser with RawArgs
+	Args []string
+
+	Parent *Invocation
+	// invocation of the selected subparser
+	Sub *Invocation
+
+	set    map[*Option]bool
+	values map[*Option][]string
+	// deprecated options already warned about, kept by the root
+	warned map[*Option]bool
+
+	// not nil when parsing for completion
+	completion *completionState
+
+	// override the input, output and exit of the parsers, see Repl
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	exit   func(code int)
+}
+
+func newInvocation(parser *ArgParser, parent *Invocation) *Invocation {
+	inv := &Invocation{
+		Parser: parser,
+		Parent: parent,
+		set:    map[*Option]bool{},
+		values: map[*Option][]string{},
+		warned: map[*Option]bool{},
+	}
+	if parent != nil {
+		inv.completion = parent.completion
+		inv.stdin = parent.stdin
+		inv.stdout = parent.stdout
+		inv.stderr = parent.stderr
+		inv.exit = parent.exit
+	}
+	return inv
+}
+
+// return the reader prompts, secrets and plugins read from, the Stdin of
+// its parser unless it runs in a Repl
+func (i *Invocation) Stdin() io.Reader {
+	if i.stdin != nil {
+		return i.stdin
+	}
+	return i.Parser.stdin()
+}
+
+// return the writer the output of the invocation goes to, the Stdout of its
+// parser unless it runs in a Repl. handlers should write to it
+func (i *Invocation) Stdout() io.Writer {
+	if i.stdout != nil {
+		return i.stdout
+	}
+	return i.Parser.stdout()
+}
+
+// same as Stdout for errors and warnings
+func (i *Invocation) Stderr() io.Writer {
+	if i.stderr != nil {
+		return i.stderr
+	}
+	return i.Parser.stderr()
+}
+
+func (i *Invocation) doExit(code int) {
+	if i.exit != nil {
+		i.exit(code)
+		return
+	}
+	i.Parser.exit(code)
+}
+
+// return the innermost selected invocation
+func (i *Invocation) Leaf() *Invocation {
+	for i.Sub != nil {
+		i = i.Sub
+	}
+	return i
+}
+
+// return root invocation
+func (i *Invocation) Root() *Invocation {
+	for i.Parent != nil {
+		i = i.Parent
+	}
+	return i
+}
+
+// reports whether option (or positional) name was given. persistent
+// options of parent parsers are also considered
+func (i *Invocation) IsSet(name string) bool {
+	inv, opt := i.resolve(name)
+	if opt == nil {
+		return false
+	}
+	return inv.set[opt]
+}
+
+// return all arguments consumed by option (or positional) name
+func (i *Invocation) Values(name string) []string {
+	inv, opt := i.resolve(name)
+	if opt == nil {
+		return nil
+	}
+	tmp := make([]string, 0, len(inv.values[opt]))
+	tmp = append(tmp, inv.values[opt]...)
+	return tmp
+}
+
+// return the last argument consumed by option (or positional) name
+func (i *Invocation) Value(name string) string {
+	values := i.Values(name)
+	if len(values) == 0 {
+		return ""
+	}
+	return values[len(values)-1]
+}
+
+func (i *Invocation) setOption(opt *Option, args ...string) {
+	opt = i.Parser.base(opt)
+	i.set[opt] = true
+	i.values[opt] = append(i.values[opt], args...)
+}
+
+// return the invocation holding the state of option (or positional) name
+func (i *Invocation) resolve(name string) (*Invocation, *Option) {
+	if opt := i.Parser.lookup(name); opt != nil {
+		return i, opt
+	}
+	opt, parser := i.Parser.findOption(name)
+	if opt == nil {
+		return nil, nil
+	}
+	for inv := i.Parent; inv != nil; inv = inv.Parent {
+		if inv.Parser == parser {
+			return inv, parser.base(opt)
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,85 @@
+package argparse
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestInvocationValues(t *testing.T) {
+	parser := New()
+	parser.AddOptionWithAlias(Option{Name: "n", Nargs: 1, Required: true}, "name")
+	parser.AddOption(Option{Name: "v"})
+	parser.AddOption(Option{Name: "rest", Positional: true, Nargs: -1})
+
+	inv, err := parser.ParseInvocation("--name", "foo", "a", "-n", "bar", "b")
+	assertError(t, false, err)
+	assertEqual(t, true, inv.IsSet("n"))
+	assertEqual(t, true, inv.IsSet("name"))
+	assertEqual(t, false, inv.IsSet("v"))
+	assertEqual(t, "bar", inv.Value("name"))
+	assertSliceEqual(t, []string{"foo", "bar"}, inv.Values("n"))
+	assertSliceEqual(t, []string{"a", "b"}, inv.Values("rest"))
+
+	_, err = parser.ParseInvocation("-v")
+	assertError(t, true, err)
+}
+
+func TestConcurrentParse(t *testing.T) {
+	parser := New()
+	parser.AddOption(Option{Name: "prefix", Nargs: 1})
+
+	add := New()
+	add.AddOption(Option{Name: "file", Positional: true, Nargs: 1, Required: true})
+	parser.AddSubParser("add", add)
+
+	del := New()
+	del.AddOption(Option{Name: "f"})
+	del.AddOption(Option{Name: "file", Positional: true, Nargs: 1, Required: true})
+	parser.AddSubParser("del", del)
+
+	sub := New()
+	sub.AddOption(Option{Name: "n", Nargs: 1})
+	del.AddSubParser("sub", sub)
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 64; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			prefix := fmt.Sprint(i)
+			file := fmt.Sprintf("file%d", i)
+
+			var (
+				inv *Invocation
+				err error
+			)
+
+			switch i % 3 {
+			case 0:
+				inv, err = parser.ParseInvocation("--prefix", prefix, "add", file)
+				assertError(t, false, err)
+				assertEqual(t, add, inv.SubParser)
+				assertEqual(t, file, inv.Sub.Value("file"))
+			case 1:
+				inv, err = parser.ParseInvocation("--prefix", prefix, "del", "-f", file)
+				assertError(t, false, err)
+				assertEqual(t, del, inv.SubParser)
+				assertEqual(t, true, inv.Sub.IsSet("f"))
+				assertEqual(t, file, inv.Sub.Value("file"))
+			case 2:
+				inv, err = parser.ParseInvocation("--prefix", prefix, "del", file, "sub", "-n", file)
+				assertError(t, false, err)
+				assertEqual(t, sub, inv.Leaf().Parser)
+				assertEqual(t, "sub", inv.Sub.SubParserName)
+				assertEqual(t, file, inv.Leaf().Value("n"))
+				assertEqual(t, inv, inv.Leaf().Root())
+			}
+			assertEqual(t, prefix, inv.Value("prefix"))
+
+			_, err = parser.ParseInvocation("--prefix", prefix, "add")
+			assertError(t, true, err)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,263 @@
+package argparse
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+type ManSection struct {
+	// section name, e.g. ENVIRONMENT, EXAMPLES or SEE ALSO
+	Title string
+	Body  string
+}
+
+type ManOptions struct {
+	// manual section. defaults to "1"
+	Section string
+	Date    string
+	Source  string
+	Manual  string
+	// return extra sections for the page of a parser. they are written
+	// after the generated ones
+	Sections func(a *ArgParser) []ManSection
+}
+
+func (o *ManOptions) section() string {
+	if len(o.Section) == 0 {
+		return "1"
+	}
+	return o.Section
+}
+
+func (a *ArgParser) manName() string {
+	return strings.Join(a.commandPath(), "-")
+}
+
+// writes the roff man page of a
+func (a *ArgParser) ManPage(w io.Writer, opts ManOptions) error {
+	name := strings.Join(a.commandPath(), " ")
+	b := &strings.Builder{}
+
+	fmt.Fprintf(b, ".TH %s %s %s %s %s\n",
+		roffQuote(strings.ToUpper(a.manName())), roffQuote(opts.section()),
+		roffQuote(opts.Date), roffQuote(opts.Source), roffQuote(opts.Manual))
+
+	b.WriteString(".SH NAME\n")
+	if len(a.Description) > 0 {
+		fmt.Fprintf(b, "%s \\- %s\n", roffEscape(name), roffEscape(flatten(firstSentence(a.Description))))
+	} else {
+		b.WriteString(roffEscape(name) + "\n")
+	}
+
+	b.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(b, ".B %s\n", roffEscape(name))
+	if strs := a.usageStrings(); len(strs) > 0 {
+		b.WriteString(roffText(strings.Join(strs, " ")) + "\n")
+	}
+
+	if len(a.Description) > 0 {
+		b.WriteString(".SH DESCRIPTION\n")
+		b.WriteString(roffParagraphs(a.Description))
+	}
+
+	for _, group := range a.Help().Groups {
+		a.writeManOptions(b, group)
+	}
+
+	subnames := a.subParserNames(false)
+	if len(subnames) > 0 {
+		b.WriteString(".SH COMMANDS\n")
+		for _, subname := range subnames {
+			sub := a.subparsers[subname]
+			b.WriteString(".TP\n")
+			str := "\\fB" + roffEscape(subname) + "\\fR"
+			if len(sub.subaliases) > 0 {
+				str += " (" + roffEscape(strings.Join(sub.subaliases, ", ")) + ")"
+			}
+			b.WriteString(str + "\n")
+			if len(sub.Description) > 0 {
+				b.WriteString(roffText(flatten(sub.Description)) + "\n")
+				b.WriteString(".br\n")
+			}
+			fmt.Fprintf(b, "See \\fB%s\\fR(%s).\n", roffEscape(sub.manName()), roffEscape(opts.section()))
+		}
+	}
+
+	if len(a.Examples) > 0 {
+		b.WriteString(".SH EXAMPLES\n")
+		for _, example := range a.Examples {
+			b.WriteString(".TP\n")
+			b.WriteString("\\fB" + roffEscape(example.Command) + "\\fR\n")
+			if len(example.Description) > 0 {
+				b.WriteString(roffText(flatten(example.Description)) + "\n")
+			}
+		}
+	}
+
+	if len(a.Epilog) > 0 {
+		b.WriteString(".SH NOTES\n")
+		b.WriteString(roffParagraphs(a.Epilog))
+	}
+
+	if opts.Sections != nil {
+		for _, section := range opts.Sections(a) {
+			fmt.Fprintf(b, ".SH %s\n", roffEscape(strings.ToUpper(section.Title)))
+			b.WriteString(roffParagraphs(section.Body))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// writes the man page of a and every visible subparser to dir, named
+// after the command path, e.g. "tool-remote-add.1"
+func (a *ArgParser) WriteManPages(dir string, opts ManOptions) error {
+	if err := a.writeManPage(dir, opts); err != nil {
+		return err
+	}
+	for _, subname := range a.subParserNames(false) {
+		if err := a.subparsers[subname].WriteManPages(dir, opts); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (a *ArgParser) writeManPage(dir string, opts ManOptions) error {
+	f, err := os.Create(filepath.Join(dir, a.manName()+"."+opts.section()))
+	if err != nil {
+		return err
+	}
+	if err := a.ManPage(f, opts); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func (a *ArgParser) writeManOptions(b *strings.Builder, group HelpGroup) {
+	fmt.Fprintf(b, ".SH %s\n", roffEscape(strings.ToUpper(group.Title)))
+	if len(group.Description) > 0 {
+		b.WriteString(roffParagraphs(group.Description))
+	}
+	for _, opt := range group.Options {
+		b.WriteString(".TP\n")
+		names := make([]string, 0, len(opt.Names))
+		for _, name := range opt.Names {
+			if opt.Positional {
+				names = append(names, "\\fI"+roffEscape(a.metavar(opt.Option))+"\\fR")
+			} else {
+				names = append(names, "\\fB"+roffEscape(name)+"\\fR")
+			}
+		}
+		str := strings.Join(names, ", ")
+		if len(opt.Metavar) > 0 {
+			str += " \\fI" + roffEscape(opt.Metavar) + "\\fR"
+		}
+		b.WriteString(str + "\n")
+		if len(opt.Description) > 0 {
+			b.WriteString(roffText(flatten(opt.Description)) + "\n")
+		}
+	}
+}
+
+// return text split into .PP separated paragraphs. list items (see bullet)
+// become indented paragraphs and other indented lines are kept as they are
+// in a preformatted block
+func roffParagraphs(text string) string {
+	b := &strings.Builder{}
+	first := true
+	for _, paragraph := range strings.Split(strings.ReplaceAll(strings.TrimSpace(text), "\r\n", "\n"), "\n\n") {
+		if len(strings.TrimSpace(paragraph)) == 0 {
+			continue
+		}
+		if !first {
+			b.WriteString(".PP\n")
+		}
+		first = false
+
+		words := make([]string, 0)
+		preformatted := make([]string, 0)
+		item := false
+		flush := func() {
+			if len(words) > 0 {
+				b.WriteString(roffText(strings.Join(words, " ")) + "\n")
+				words = words[:0]
+			}
+			if len(preformatted) > 0 {
+				indent := len(preformatted[0])
+				for _, line := range preformatted {
+					if n := len(line) - len(strings.TrimLeft(line, " \t")); n < indent && n < len(line) {
+						indent = n
+					}
+				}
+				b.WriteString(".RS 4\n.nf\n")
+				for _, line := range preformatted {
+					if len(line) >= indent {
+						line = line[indent:]
+					}
+					b.WriteString(roffText(line) + "\n")
+				}
+				b.WriteString(".fi\n.RE\n")
+				preformatted = preformatted[:0]
+			}
+		}
+
+		for _, line := range strings.Split(paragraph, "\n") {
+			indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+			if marker, ok := bullet(line); ok {
+				flush()
+				line = strings.TrimPrefix(strings.TrimSpace(line), marker)
+				if marker == "-" || marker == "*" || marker == "•" {
+					b.WriteString(".IP \\(bu 4\n")
+				} else {
+					fmt.Fprintf(b, ".IP %s 4\n", roffQuote(marker))
+				}
+				item = true
+			} else if indented && !item {
+				if len(words) > 0 {
+					flush()
+				}
+				preformatted = append(preformatted, strings.TrimRight(line, " \t"))
+				continue
+			} else if len(preformatted) > 0 {
+				flush()
+			}
+			words = append(words, strings.Fields(line)...)
+		}
+		flush()
+	}
+	return b.String()
+}
+
+// return s escaped, protecting it from being read as a request
+func roffText(s string) string {
+	s = roffEscape(s)
+	if strings.HasPrefix(s, ".") || strings.HasPrefix(s, "'") {
+		s = "\\&" + s
+	}
+	return s
+}
+
+func roffEscape(s string) string {
+	return strings.NewReplacer(`\`, `\e`, `-`, `\-`).Replace(s)
+}
+
+func roffQuote(s string) string {
+	return `"` + strings.ReplaceAll(roffEscape(s), `"`, `\(dq`) + `"`
+}
+
+func firstSentence(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "\n\n"); i >= 0 {
+		s = s[:i]
+	}
+	if i := strings.Index(s, ". "); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimSuffix(s, ".")
+}
@@ -0,0 +1,43 @@
+package argparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestManPages(t *testing.T) {
+	parser := toolTestParser()
+	parser.Description = "tool manages remotes. it also\nshows logs.\n\n.dotted paragraph with a \\ backslash"
+
+	parser.AddExample("tool remote add origin", "adds a remote named origin")
+	parser.AddExample("tool log", "")
+	parser.Epilog = "report bugs to <bugs@example.com>"
+	opts := ManOptions{Date: "2024-01-01", Source: "tool 1.0", Manual: "Tool Manual", Sections: func(a *ArgParser) []ManSection {
+		if a != parser {
+			return nil
+		}
+		return []ManSection{
+			{Title: "environment", Body: "TOOL_CONFIG\n    default config file"},
+			{Title: "files", Body: "read in order:\n- ~/.toolrc\n- /etc/tool.conf, which is\n  shared\n\n1. first\n2. second"},
+			{Title: "see also", Body: "git(1)"},
+		}
+	}}
+
+	dir := t.TempDir()
+	assertError(t, false, parser.WriteManPages(dir, opts))
+
+	entries, err := os.ReadDir(dir)
+	assertError(t, false, err)
+	names := make([]string, 0)
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assertSliceEqual(t, []string{"tool-log.1", "tool-remote-add.1", "tool-remote.1", "tool.1"}, names)
+
+	for _, name := range []string{"tool.1", "tool-remote.1", "tool-remote-add.1"} {
+		page, err := os.ReadFile(filepath.Join(dir, name))
+		assertError(t, false, err)
+		assertGolden(t, name, page)
+	}
+}
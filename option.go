@@ -8,9 +8,39 @@ type Option struct {
 	Required    bool
 	Metavar     string
 	Description string
+	// also accepted by every subparser after the parser it was added to
+	Persistent bool
+	// if not empty, arguments must be one of these
+	Choices []string
+	// value shown in the usage and docs as the default. the parser does
+	// not apply it, the variable set by Callback should start with it
+	Default string
+	// environment variable shown in the usage and docs as a source of the
+	// value. the parser does not read it
+	Env string
+	// return completion candidates for an argument starting with prefix.
+	// a candidate may be followed by a tab and its description. used by the
+	// completion scripts only once AddCompletionSubParser was called
+	Completer func(ctx *Context, prefix string) []string
+	// left out of the errors of the option types of this package, redacted
+	// in the usage, docs and Invocation dumps, and prompted for without
+	// echo, see ArgParser.PromptRequired. custom callbacks should not put
+	// the value in their errors
+	Secret bool
+	// for Secret options, also accept the value from a file with the added
+	// option "--<name>-file", or from Stdin if the value is "-"
+	SecretFile bool
+	// dont show in the usage, docs and completions
+	Hidden bool
+	// if not empty, a warning shown once per parse when the option is given
+	Deprecated string
+	// name of the option receiving the arguments of this one instead. it
+	// must be added to the same parser before this one and take the same
+	// number of arguments
+	Replacement string
 
 	basealias string
-	set       bool
+	group     *OptionGroup
 	sort      int
 }
 
@@ -26,7 +56,20 @@ func (o *Option) String() string {
 	return "--" + o.Name
 }
 
-func (o *Option) string() string {
+func (o *Option) isChoice(val string) bool {
+	if len(o.Choices) == 0 {
+		return true
+	}
+	for _, choice := range o.Choices {
+		if choice == val {
+			return true
+		}
+	}
+	return false
+}
+
+// return o as shown in the usage line
+func (o *Option) usage(metavar string) string {
 	tmp := o.String()
 
 	if o.Positional && len(o.Metavar) > 0 {
@@ -34,10 +77,6 @@ func (o *Option) string() string {
 	}
 
 	if o.Nargs > 0 && !o.Positional {
-		metavar := o.Metavar
-		if len(metavar) == 0 {
-			metavar = "var"
-		}
 		tmp += " " + metavar
 	}
 
@@ -68,6 +107,56 @@ func (o Option) SetDescription(val string) Option {
 	return o
 }
 
+func (o Option) SetPersistent(val bool) Option {
+	o.Persistent = val
+	return o
+}
+
+func (o Option) SetChoices(val ...string) Option {
+	o.Choices = val
+	return o
+}
+
+func (o Option) SetCompleter(val func(ctx *Context, prefix string) []string) Option {
+	o.Completer = val
+	return o
+}
+
+func (o Option) SetDefault(val string) Option {
+	o.Default = val
+	return o
+}
+
+func (o Option) SetEnv(val string) Option {
+	o.Env = val
+	return o
+}
+
+func (o Option) SetSecret(val bool) Option {
+	o.Secret = val
+	return o
+}
+
+func (o Option) SetSecretFile(val bool) Option {
+	o.SecretFile = val
+	return o
+}
+
+func (o Option) SetHidden(val bool) Option {
+	o.Hidden = val
+	return o
+}
+
+func (o Option) SetDeprecated(val string) Option {
+	o.Deprecated = val
+	return o
+}
+
+func (o Option) SetReplacement(val string) Option {
+	o.Replacement = val
+	return o
+}
+
 func (o Option) SetAll(required bool, description, metavar string) Option {
 	return o.SetRequired(required).SetMetavar(metavar).SetDescription(description)
 }
@@ -0,0 +1,94 @@
+package argparse
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func (a *ArgParser) pluginPrefix() string {
+	if len(a.PluginPrefix) > 0 {
+		return a.PluginPrefix
+	}
+	if len(strings.Fields(a.Name)) == 0 {
+		return ""
+	}
+	return strings.Join(a.commandPath(), "-") + "-"
+}
+
+func (a *ArgParser) pluginDirs() []string {
+	if a.PluginDirs != nil {
+		return a.PluginDirs
+	}
+	return filepath.SplitList(os.Getenv("PATH"))
+}
+
+// return the path of the executable implementing command name, or an
+// empty string if there is none
+func (a *ArgParser) findPlugin(name string) string {
+	prefix := a.pluginPrefix()
+	if !a.Plugins || len(prefix) == 0 || len(name) == 0 || strings.HasPrefix(name, "-") || strings.ContainsRune(name, filepath.Separator) {
+		return ""
+	}
+	for _, dir := range a.pluginDirs() {
+		path := filepath.Join(dir, prefix+name)
+		if isExecutable(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// return the names of every plugin found, excluding the ones shadowed by a
+// subparser
+func (a *ArgParser) pluginNames() []string {
+	prefix := a.pluginPrefix()
+	if !a.Plugins || len(prefix) == 0 {
+		return []string{}
+	}
+
+	seen := map[string]bool{}
+	names := make([]string, 0)
+	for _, dir := range a.pluginDirs() {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			name := strings.TrimPrefix(entry.Name(), prefix)
+			if name == entry.Name() || len(name) == 0 || seen[name] {
+				continue
+			}
+			if _, ok := a.subparsers[name]; ok {
+				continue
+			}
+			if isExecutable(filepath.Join(dir, entry.Name())) {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+
+	sort.Strings(names)
+	return names
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false
+	}
+	return info.Mode().IsRegular() && info.Mode().Perm()&0111 != 0
+}
+
+// executes the selected plugin with the remaining arguments
+func (i *Invocation) RunPlugin(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, i.Plugin, i.PluginArgs...)
+	cmd.Stdin = i.Stdin()
+	cmd.Stdout = i.Stdout()
+	cmd.Stderr = i.Stderr()
+	return cmd.Run()
+}
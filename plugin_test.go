@@ -0,0 +1,63 @@
+package argparse
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writePlugin(t *testing.T, dir, name string) {
+	t.Helper()
+	script := "#!/bin/sh\necho \"$0\" \"$@\" > \"$(dirname \"$0\")/out\"\n"
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPlugin(t *testing.T) {
+	dir := t.TempDir()
+	writePlugin(t, dir, "tool-hello")
+	writePlugin(t, dir, "tool-add")
+	writePlugin(t, dir, "other-foo")
+	if err := os.WriteFile(filepath.Join(dir, "tool-noexec"), []byte{}, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	parser := New()
+	parser.Width = 80
+	parser.Name = "/usr/bin/tool"
+	parser.AddOption(Option{Name: "v"})
+	parser.AddSubParser("add", New())
+
+	assertError(t, true, parser.Parse("hello"))
+
+	parser.Plugins = true
+	parser.PluginDirs = []string{dir}
+
+	inv, err := parser.ParseInvocation("-v", "hello", "-x", "world")
+	assertError(t, false, err)
+	assertEqual(t, true, inv.IsSet("v"))
+	assertEqual(t, "hello", inv.PluginName)
+	assertEqual(t, filepath.Join(dir, "tool-hello"), inv.Plugin)
+	assertSliceEqual(t, []string{"-x", "world"}, inv.PluginArgs)
+
+	inv, err = parser.ParseInvocation("add")
+	assertError(t, false, err)
+	assertEqual(t, "", inv.Plugin)
+
+	assertError(t, true, parser.Parse("noexec"))
+	assertError(t, true, parser.Parse("foo"))
+
+	assertError(t, false, parser.ExecuteContext(context.Background(), "hello", "a", "b"))
+	out, err := os.ReadFile(filepath.Join(dir, "out"))
+	assertError(t, false, err)
+	assertEqual(t, filepath.Join(dir, "tool-hello")+" a b\n", string(out))
+
+	assertEqual(t, "usage: /usr/bin/tool [-v] <command> \n\n"+
+		"options:\n"+
+		"    -v\n\n"+
+		"commands:\n"+
+		"    add\n"+
+		"    hello\n", parser.Usage())
+}
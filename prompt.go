@@ -0,0 +1,150 @@
+package argparse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+func (a *ArgParser) stdin() io.Reader {
+	for p := a; p != nil; p = p.parent {
+		if p.Stdin != nil {
+			return p.Stdin
+		}
+	}
+	return os.Stdin
+}
+
+func (a *ArgParser) promptRequired() bool {
+	for p := a; p != nil; p = p.parent {
+		if p.PromptRequired {
+			return true
+		}
+	}
+	return false
+}
+
+// prompts for the values of required options that were not given, until
+// they are accepted by their callback. fails if input ends
+func (c *Context) promptMissing() error {
+	in, out := c.inv.Stdin(), c.inv.Stderr()
+
+	for _, opt := range c.parser.Options() {
+		if !opt.Required || opt.Nargs == 0 || len(opt.basealias) != 0 || c.inv.set[opt] {
+			continue
+		}
+
+		label := opt.String()
+		if !opt.Positional {
+			label += " " + c.parser.metavar(opt)
+		}
+		if len(opt.Description) > 0 {
+			label = flatten(opt.Description) + " (" + label + ")"
+		}
+
+		for {
+			fmt.Fprintf(out, "%s: ", label)
+			line, err := readLine(in, opt.Secret)
+			if opt.Secret {
+				fmt.Fprintln(out)
+			}
+			if err != nil {
+				return fmt.Errorf("option %s is required", opt.String())
+			}
+
+			args := []string{line}
+			if opt.Nargs > 1 {
+				args = strings.Fields(line)
+				if len(args) != opt.Nargs {
+					c.parser.WriteError(out, fmt.Errorf("option %s requires %d arguments", opt.String(), opt.Nargs))
+					continue
+				}
+			}
+
+			if err := c.call(opt, args...); err != nil {
+				c.parser.WriteError(out, err)
+				continue
+			}
+			if c.err != nil {
+				c.parser.WriteError(out, c.err)
+				c.err = nil
+				c.abort = false
+				continue
+			}
+
+			c.inv.setOption(opt, args...)
+			break
+		}
+	}
+	return nil
+}
+
+// return a line read from in without the line break. in is read a byte at
+// a time so nothing after the line is consumed. if secret and in is a
+// terminal, the input is not echoed, or an error is returned if echo
+// cannot be disabled
+func readLine(in io.Reader, secret bool) (string, error) {
+	if f, ok := in.(*os.File); ok && secret && term.IsTerminal(int(f.Fd())) {
+		return readPassword(f)
+	}
+
+	line := make([]byte, 0)
+	b := make([]byte, 1)
+	for {
+		n, err := in.Read(b)
+		if n > 0 {
+			if b[0] == '\n' {
+				return strings.TrimSuffix(string(line), "\r"), nil
+			}
+			line = append(line, b[0])
+		}
+		if errors.Is(err, io.EOF) && len(line) > 0 {
+			return string(line), nil
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+}
+
+// return a line read from the terminal f without echoing it. the terminal
+// is restored if the process is interrupted or terminated meanwhile, and
+// the signal is then delivered again
+func readPassword(f *os.File) (string, error) {
+	fd := int(f.Fd())
+	state, err := term.GetState(fd)
+	if err != nil {
+		return "", fmt.Errorf("cannot hide input: %w", err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer func() {
+		signal.Stop(signals)
+		close(done)
+	}()
+	go func() {
+		select {
+		case sig := <-signals:
+			term.Restore(fd, state)
+			signal.Stop(signals)
+			if p, err := os.FindProcess(os.Getpid()); err != nil || p.Signal(sig) != nil {
+				os.Exit(1)
+			}
+		case <-done:
+		}
+	}()
+
+	line, err := term.ReadPassword(fd)
+	if err != nil {
+		return "", fmt.Errorf("cannot hide input: %w", err)
+	}
+	return string(line), nil
+}
@@ -0,0 +1,66 @@
+package argparse
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestPromptRequired(t *testing.T) {
+	var (
+		port     int
+		password string
+		name     string
+	)
+	stderr := &strings.Builder{}
+
+	parser := New()
+	parser.AddOption(Int("port", &port).SetRequired(true).SetDescription("server port").SetMetavar("n"))
+	parser.AddOption(String("password", &password).SetRequired(true).SetSecret(true))
+	parser.AddOption(StringPositional("name", &name).SetRequired(true))
+	parser.Stderr = stderr
+	parser.Stdin = strings.NewReader("eighty\n80\nhunter2\n")
+
+	err := parser.Parse()
+	assertError(t, true, err)
+	assertEqual(t, "the following options are required: --port, --password, name", err.Error())
+
+	parser.PromptRequired = true
+	parser.Stdin = strings.NewReader("eighty\n80\nhunter2\nfoo bar")
+	inv, err := parser.ParseInvocation()
+	assertError(t, false, err)
+	assertEqual(t, 80, port)
+	assertEqual(t, "hunter2", password)
+	assertEqual(t, "foo bar", name)
+	assertEqual(t, true, inv.IsSet("port"))
+	assertEqual(t, "server port (--port n): error: option --port \"eighty\" requires an integer\n"+
+		"server port (--port n): --password var: \n"+
+		"name: ", stderr.String())
+
+	// given options are not prompted for
+	stderr.Reset()
+	parser.Stdin = strings.NewReader("")
+	assertError(t, false, parser.Parse("--port", "1", "--password", "x", "y"))
+	assertEqual(t, "", stderr.String())
+
+	parser.Stdin = strings.NewReader("81\n")
+	err = parser.Parse()
+	assertError(t, true, err)
+	assertEqual(t, "option --password is required", err.Error())
+}
+
+func TestReadLine(t *testing.T) {
+	// pipes are read as is, even for secrets
+	r, w, err := os.Pipe()
+	assertError(t, false, err)
+	defer r.Close()
+	w.WriteString("s3cret\r\nrest")
+	w.Close()
+
+	line, err := readLine(r, true)
+	assertError(t, false, err)
+	assertEqual(t, "s3cret", line)
+	line, err = readLine(r, true)
+	assertError(t, false, err)
+	assertEqual(t, "rest", line)
+}
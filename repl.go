@@ -0,0 +1,100 @@
+package argparse
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// runs an interactive shell over a. see ReplContext
+func (a *ArgParser) Repl(in io.Reader, out io.Writer) error {
+	return a.ReplContext(context.Background(), in, out)
+}
+
+// reads lines from in, splits them like a shell does and executes them
+// with a fresh Invocation each. errors are written to out without stopping.
+// "exit" and "quit" stop it, and "help [command...]" shows the usage of a
+// command unless a has a "help" subparser. the invocations read from in,
+// write to out (see Invocation.Stdin and Invocation.Stdout) and do not exit
+func (a *ArgParser) ReplContext(ctx context.Context, in io.Reader, out io.Writer) error {
+	prompt := a.Prompt
+	if len(prompt) == 0 {
+		prompt = a.commandPath()[0] + "> "
+	}
+
+	for {
+		if _, err := io.WriteString(out, prompt); err != nil {
+			return err
+		}
+		// read a line at a time, leaving the next ones to the prompts
+		line, err := readLine(in, false)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		args, err := Split(line)
+		if err != nil {
+			a.WriteError(out, err)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+
+		switch args[0] {
+		case "exit", "quit":
+			return nil
+		case "help":
+			if _, ok := a.subparsers["help"]; !ok {
+				if p, err := a.subParserAt(args[1:]...); err != nil {
+					a.WriteError(out, err)
+				} else {
+					p.WriteHelp(out)
+				}
+				continue
+			}
+		}
+
+		inv := newInvocation(a, nil)
+		inv.stdin, inv.stdout, inv.stderr, inv.exit = in, out, out, func(code int) {}
+		if err = a.parse(inv, args...); err == nil {
+			err = inv.Execute(ctx)
+		}
+		if err != nil && !errors.Is(err, ErrHelp) && !errors.Is(err, ErrVersion) {
+			a.WriteError(out, err)
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+// return the completion candidates of the last word of line, as typed in
+// Repl. meant to be used by line editors
+func (a *ArgParser) CompleteLine(line string) []string {
+	args, err := Split(line)
+	if err != nil {
+		return []string{}
+	}
+	if len(args) == 0 || strings.HasSuffix(line, " ") {
+		args = append(args, "")
+	}
+
+	candidates, _ := a.Complete(args...)
+	if len(args) == 1 {
+		candidates = append(candidates, filterCandidates([]string{"exit", "quit"}, args[0])...)
+		if _, ok := a.subparsers["help"]; !ok {
+			candidates = append(candidates, filterCandidates([]string{"help"}, args[0])...)
+		}
+	}
+
+	words := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		words = append(words, strings.SplitN(candidate, "\t", 2)[0])
+	}
+	return words
+}
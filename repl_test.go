@@ -0,0 +1,62 @@
+package argparse
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestRepl(t *testing.T) {
+	parser := NewWithDefaults()
+	parser.Name = "tool"
+
+	add := New()
+	add.Description = "adds a remote"
+	add.AddOption(Option{Name: "name", Positional: true, Nargs: 1, Required: true})
+	add.Run = func(ctx context.Context, inv *Invocation) error {
+		fmt.Fprintf(inv.Stdout(), "added %s\n", inv.Value("name"))
+		return nil
+	}
+	parser.AddSubParser("add", add)
+
+	// output of subparsers with their own streams goes to out too
+	other := &strings.Builder{}
+	add.Stdout, add.Stderr = other, other
+	add.Exit = func(code int) { t.Errorf("exited with %d", code) }
+
+	in := strings.NewReader("add 'a b'\n\nadd\nhelp add\nadd --help\nadd \"x\nnope\nexit\nadd c\n")
+	out := &strings.Builder{}
+	assertError(t, false, parser.Repl(in, out))
+
+	assertEqual(t, "tool> added a b\n"+
+		"tool> "+
+		"tool> error: option name is required\n"+
+		"tool> "+add.Usage()+
+		"tool> "+add.Usage()+
+		"tool> error: unterminated double quote at position 5\n"+
+		"tool> error: unexpected operand \"nope\"\n"+
+		"tool> ", out.String())
+
+	assertEqual(t, "", other.String())
+	// the parsers are not modified
+	if parser.Stdout != nil || parser.Exit != nil || add.Stdout != other {
+		t.Errorf("expected the parsers streams to be unchanged")
+	}
+
+	parser.Prompt = "$ "
+	out.Reset()
+	assertError(t, false, parser.Repl(strings.NewReader("add d"), out))
+	assertEqual(t, "$ added d\n$ ", out.String())
+
+	// prompts read the following lines of in
+	parser.PromptRequired = true
+	add.Stdin = strings.NewReader("wrong\n")
+	out.Reset()
+	assertError(t, false, parser.Repl(strings.NewReader("add\nbob\nadd eve\n"), out))
+	assertEqual(t, "$ name: added bob\n$ added eve\n$ ", out.String())
+
+	assertSliceEqual(t, []string{"add", "exit", "quit", "help"}, parser.CompleteLine(""))
+	assertSliceEqual(t, []string{"add"}, parser.CompleteLine("a"))
+	assertSliceEqual(t, []string{"-h", "--help"}, parser.CompleteLine("add -"))
+}
@@ -0,0 +1,69 @@
+package argparse
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// return the prefix of response files, whether they have one argument per
+// line and whether they are expanded
+func (a *ArgParser) responseFiles() (string, bool, bool) {
+	for p := a; p != nil; p = p.parent {
+		if p.ResponseFiles {
+			if len(p.ResponseFilePrefix) == 0 {
+				return "@", p.ResponseFileLines, true
+			}
+			return p.ResponseFilePrefix, p.ResponseFileLines, true
+		}
+	}
+	return "", false, false
+}
+
+// return the arguments of the response file path. each line is an
+// argument if lines is true, otherwise it is split like a shell does, see
+// Split, and a word starting with # comments out the rest of the line.
+// arguments starting with prefix are also expanded. stack holds the
+// files being read, to detect cycles
+func readResponseFile(path, prefix string, lines bool, stack []string) ([]string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, tmp := range stack {
+		if tmp == abs {
+			return nil, fmt.Errorf("response file %s includes itself", path)
+		}
+	}
+	stack = append(stack, abs)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	args := make([]string, 0)
+	for i, line := range strings.Split(string(data), "\n") {
+		var words []string
+		if lines {
+			if line = strings.TrimSuffix(line, "\r"); len(line) > 0 {
+				words = []string{line}
+			}
+		} else if words, err = split(line, true); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+		}
+		for _, word := range words {
+			if !strings.HasPrefix(word, prefix) || len(word) == len(prefix) {
+				args = append(args, word)
+				continue
+			}
+			tmp, err := readResponseFile(word[len(prefix):], prefix, lines, stack)
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, i+1, err)
+			}
+			args = append(args, tmp...)
+		}
+	}
+	return args, nil
+}
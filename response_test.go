@@ -0,0 +1,69 @@
+package argparse
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResponseFiles(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		assertError(t, false, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	inner := write("inner.txt", "--tag 'c d'\n")
+	outer := write("outer.txt", "# flags\n--name \"a b\"\n--tag x\n@"+inner+"\n\n")
+
+	var (
+		name string
+		tags []string
+	)
+	parser := New()
+	parser.AddOption(String("name", &name))
+	parser.AddOption(StringAppend("tag", &tags))
+
+	assertError(t, true, parser.Parse("@"+outer))
+
+	parser.ResponseFiles = true
+	assertError(t, false, parser.Parse("@"+outer, "--tag", "e"))
+	assertEqual(t, "a b", name)
+	assertSliceEqual(t, []string{"x", "c d", "e"}, tags)
+
+	parser.ResponseFilePrefix = "+"
+	tags = nil
+	assertError(t, false, parser.Parse("+"+inner))
+	assertSliceEqual(t, []string{"c d"}, tags)
+
+	parser.ResponseFilePrefix = ""
+	bad := write("bad.txt", "--name ok\n--name 'oops\n")
+	err := parser.Parse("@" + bad)
+	assertError(t, true, err)
+	assertEqual(t, bad+":2: unterminated single quote at position 8", err.Error())
+
+	// backslashes are escapes unless quoted
+	name = ""
+	tags = nil
+	paths := write("paths.txt", "--name 'C:\\Program Files\\x'\n--tag C:\\\\tmp\n--tag C:\\tmp\n")
+	assertError(t, false, parser.Parse("@"+paths))
+	assertEqual(t, `C:\Program Files\x`, name)
+	assertSliceEqual(t, []string{`C:\tmp`, "C:tmp"}, tags)
+
+	// one argument per line, taken as is
+	parser.ResponseFileLines = true
+	tags = nil
+	nested := write("nested.txt", "--tag\nc d\n")
+	lines := write("lines.txt", "--name\r\nC:\\Program Files\\x\n\n--tag\n'quoted' \"word\"\n@"+nested+"\n")
+	assertError(t, false, parser.Parse("@"+lines))
+	assertEqual(t, `C:\Program Files\x`, name)
+	assertSliceEqual(t, []string{`'quoted' "word"`, "c d"}, tags)
+	parser.ResponseFileLines = false
+
+	a := write("a.txt", "--tag a\n@"+filepath.Join(dir, "b.txt")+"\n")
+	b := write("b.txt", "@"+a+"\n")
+	err = parser.Parse("@" + a)
+	assertError(t, true, err)
+	assertEqual(t, a+":2: "+b+":1: response file "+a+" includes itself", err.Error())
+}
@@ -0,0 +1,161 @@
+package argparse
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// shown instead of the values of Secret options
+const redacted = "***"
+
+// return opt followed by value quoted, for errors about value. the value
+// is left out if opt is Secret
+func optionValue(opt *Option, value string) string {
+	if opt.Secret {
+		return opt.String()
+	}
+	return fmt.Sprintf("%s %q", opt.String(), value)
+}
+
+// replaces "-" given to a secret option accepting files by a line read
+// from Stdin
+func (c *Context) readSecret(opt *Option, args []string) error {
+	if !opt.Secret || !opt.SecretFile || len(args) != 1 || args[0] != "-" {
+		return nil
+	}
+	line, err := readLine(c.inv.Stdin(), true)
+	if err != nil {
+		return fmt.Errorf("option %s: %w", opt.String(), err)
+	}
+	args[0] = line
+	return nil
+}
+
+// adds the option "<name>-file" giving opt the content of a file, or a
+// line of Stdin if it is "-"
+func (a *ArgParser) addSecretFileOption(opt Option) {
+	if opt.Positional || opt.Nargs != 1 {
+		panic("secret file option must have nargs == 1")
+	}
+	a.AddOption(Option{
+		Name:        opt.Name + "-file",
+		Nargs:       1,
+		Metavar:     "file",
+		Description: "reads " + opt.String() + " from file",
+		Persistent:  opt.Persistent,
+		group:       opt.group,
+		Callback: func(ctx *Context, args ...string) {
+			target, _ := ctx.parser.findOption(opt.Name)
+			value := args[0]
+			if value == "-" {
+				line, err := readLine(ctx.inv.Stdin(), true)
+				if err != nil {
+					ctx.AbortWithError(fmt.Errorf("option %s: %w", ctx.Option().String(), err))
+					return
+				}
+				value = line
+			} else {
+				data, err := os.ReadFile(value)
+				if err != nil {
+					ctx.AbortWithError(fmt.Errorf("option %s: %w", ctx.Option().String(), err))
+					return
+				}
+				value = strings.TrimRight(string(data), "\r\n")
+			}
+			if err := ctx.call(target, value); err != nil {
+				ctx.AbortWithError(err)
+				return
+			}
+			ctx.owner(target).setOption(target, value)
+		},
+	})
+}
+
+// return the options set in i with their values, redacting the ones of
+// Secret options
+func (i *Invocation) redactedValues() ([]*Option, map[*Option][]string) {
+	opts := make([]*Option, 0)
+	values := map[*Option][]string{}
+	for _, opt := range i.Parser.Options() {
+		if len(opt.basealias) != 0 || !i.set[opt] {
+			continue
+		}
+		tmp := make([]string, 0, len(i.values[opt]))
+		for _, value := range i.values[opt] {
+			if opt.Secret {
+				value = redacted
+			}
+			tmp = append(tmp, value)
+		}
+		opts = append(opts, opt)
+		values[opt] = tmp
+	}
+	return opts, values
+}
+
+// return the given options and selected commands as a command line, with
+// the values of Secret options redacted. meant for logging
+func (i *Invocation) String() string {
+	args := make([]string, 0)
+	for inv := i.Root(); inv != nil; inv = inv.Sub {
+		if inv.Parent == nil {
+			args = append(args, inv.Parser.commandPath()[0])
+		} else {
+			args = append(args, inv.Parent.SubParserName)
+		}
+
+		opts, values := inv.redactedValues()
+		for _, opt := range opts {
+			if opt.Positional {
+				args = append(args, values[opt]...)
+				continue
+			}
+			if opt.Nargs == 0 {
+				args = append(args, opt.String())
+				continue
+			}
+			for j := 0; j+opt.Nargs <= len(values[opt]); j += opt.Nargs {
+				args = append(args, opt.String())
+				args = append(args, values[opt][j:j+opt.Nargs]...)
+			}
+		}
+
+		if len(inv.PluginName) > 0 {
+			args = append(args, inv.PluginName)
+			args = append(args, inv.PluginArgs...)
+		}
+		args = append(args, inv.Args...)
+	}
+	return Quote(args)
+}
+
+// encodes the options given to each selected parser, with the values of
+// Secret options redacted
+func (i *Invocation) MarshalJSON() ([]byte, error) {
+	type invocation struct {
+		Command string              `json:"command"`
+		Options map[string][]string `json:"options"`
+		Args    []string            `json:"args,omitempty"`
+		Sub     json.RawMessage     `json:"sub,omitempty"`
+	}
+
+	opts, values := i.redactedValues()
+	tmp := invocation{
+		Command: strings.Join(i.Parser.commandPath(), " "),
+		Options: map[string][]string{},
+		Args:    i.Args,
+	}
+	for _, opt := range opts {
+		tmp.Options[opt.String()] = values[opt]
+	}
+	if i.Sub != nil {
+		sub, err := i.Sub.MarshalJSON()
+		if err != nil {
+			return nil, err
+		}
+		tmp.Sub = sub
+	}
+	return json.Marshal(tmp)
+}
@@ -0,0 +1,94 @@
+package argparse
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSecret(t *testing.T) {
+	type S struct {
+		Token string `secret:"true" choices:"abc,hunter2" default:"abc" env:"TEST_ARGPARSE_TOKEN"`
+		Pin   int    `secret:"true"`
+		User  string
+	}
+
+	s := S{}
+	parser := New()
+	parser.Width = 80
+	parser.Name = "tool"
+	parser.LoadStruct(&s)
+	sub := New()
+	parser.AddSubParser("sub", sub)
+
+	assertEqual(t, true, strings.Contains(parser.Usage(), "(default: ***, env: TEST_ARGPARSE_TOKEN)"))
+
+	err := parser.Parse("--token", "letmein")
+	assertError(t, true, err)
+	assertEqual(t, `option --token is invalid, choose from: abc, hunter2`, err.Error())
+
+	err = parser.Parse("--pin", "12a4")
+	assertError(t, true, err)
+	assertEqual(t, `option --pin requires an integer`, err.Error())
+
+	inv, err := parser.ParseInvocation("--token", "hunter2", "--pin", "1234", "--user", "me", "sub")
+	assertError(t, false, err)
+	assertEqual(t, "hunter2", s.Token)
+	assertEqual(t, "hunter2", inv.Value("token"))
+	assertEqual(t, `tool --token '***' --pin '***' --user me sub`, inv.String())
+
+	data, err := json.Marshal(inv)
+	assertError(t, false, err)
+	assertEqual(t, `{"command":"tool","options":{"--pin":["***"],"--token":["***"],"--user":["me"]},"sub":{"command":"tool sub","options":{}}}`, string(data))
+
+	// values are left out where the errors are built, other errors are
+	// kept as they are
+	var (
+		count uint
+		x, y  int
+	)
+	other := New()
+	other.AddOption(Uint("count", &count).SetSecret(true))
+	other.AddOption(Sscanf("point", "%d,%d", &x, &y).SetSecret(true))
+	other.AddOption(Option{Name: "key", Nargs: 1, Secret: true, Callback: func(ctx *Context, args ...string) {
+		ctx.AbortWithError(errors.New("no such option"))
+	}})
+	assertEqual(t, "option --count requires an unsigned integer", other.Parse("--count", "-1").Error())
+	assertEqual(t, "option --point is invalid", other.Parse("--point", "1,a").Error())
+	assertEqual(t, "no such option", other.Parse("--key", "o").Error())
+}
+
+func TestSecretFile(t *testing.T) {
+	password := ""
+	parser := New()
+	parser.AddOptionWithAlias(String("password", &password).SetSecret(true).SetSecretFile(true), "p")
+
+	assertSliceEqual(t, []string{"password", "p", "password-file"}, func() []string {
+		names := make([]string, 0)
+		for _, opt := range parser.Options() {
+			names = append(names, opt.Name)
+		}
+		return names
+	}())
+
+	path := filepath.Join(t.TempDir(), "password")
+	assertError(t, false, os.WriteFile(path, []byte("from file\n"), 0600))
+	inv, err := parser.ParseInvocation("--password-file", path)
+	assertError(t, false, err)
+	assertEqual(t, "from file", password)
+	assertEqual(t, true, inv.IsSet("password"))
+
+	parser.Stdin = strings.NewReader("from stdin\n")
+	assertError(t, false, parser.Parse("-p", "-"))
+	assertEqual(t, "from stdin", password)
+
+	parser.Stdin = strings.NewReader("file stdin\n")
+	assertError(t, false, parser.Parse("--password-file", "-"))
+	assertEqual(t, "file stdin", password)
+
+	err = parser.Parse("--password-file", filepath.Join(t.TempDir(), "missing"))
+	assertError(t, true, err)
+}
@@ -0,0 +1,101 @@
+package argparse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// return s split into words like a POSIX shell does, without expansions.
+// words are separated by blanks and may be quoted with single or double
+// quotes or escaped with a backslash. # is taken literally. error
+// positions count runes from 1
+func Split(s string) ([]string, error) {
+	return split(s, false)
+}
+
+// same as Split, but a word starting with # comments out the rest of the
+// line if comments is true
+func split(s string, comments bool) ([]string, error) {
+	words := make([]string, 0)
+	word := &strings.Builder{}
+	inword := false
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			if inword {
+				words = append(words, word.String())
+				word.Reset()
+				inword = false
+			}
+		case r == '#' && !inword && comments:
+			for i < len(runes) && runes[i] != '\n' {
+				i++
+			}
+		case r == '\\':
+			inword = true
+			i++
+			if i == len(runes) {
+				return nil, fmt.Errorf("trailing backslash at position %d", i)
+			}
+			// an escaped newline joins lines
+			if runes[i] != '\n' {
+				word.WriteRune(runes[i])
+			}
+		case r == '\'':
+			inword = true
+			j := i + 1
+			for j < len(runes) && runes[j] != '\'' {
+				j++
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("unterminated single quote at position %d", i+1)
+			}
+			word.WriteString(string(runes[i+1 : j]))
+			i = j
+		case r == '"':
+			inword = true
+			j := i + 1
+			for ; j < len(runes) && runes[j] != '"'; j++ {
+				if runes[j] == '\\' && j+1 < len(runes) && strings.ContainsRune("$`\"\\\n", runes[j+1]) {
+					j++
+					if runes[j] == '\n' {
+						continue
+					}
+				}
+				word.WriteRune(runes[j])
+			}
+			if j == len(runes) {
+				return nil, fmt.Errorf("unterminated double quote at position %d", i+1)
+			}
+			i = j
+		default:
+			inword = true
+			word.WriteRune(r)
+		}
+	}
+
+	if inword {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// return args joined as a shell command line, quoting them when needed.
+// the inverse of Split
+func Quote(args []string) string {
+	quoted := make([]string, 0, len(args))
+	for _, arg := range args {
+		if len(arg) == 0 || strings.IndexFunc(arg, unsafeShellRune) >= 0 {
+			arg = shellQuote(arg)
+		}
+		quoted = append(quoted, arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func unsafeShellRune(r rune) bool {
+	return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("@%+=:,./_-", r))
+}
@@ -0,0 +1,75 @@
+package argparse
+
+import (
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"", []string{}},
+		{"  a\tb \n c ", []string{"a", "b", "c"}},
+		{`'a b' "c d" e\ f`, []string{"a b", "c d", "e f"}},
+		{`'it'\''s' "\"x\" \$y \z"`, []string{"it's", `"x" $y \z`}},
+		{`a''b "" ''`, []string{"ab", "", ""}},
+		{"a #comment\nb c#d", []string{"a", "#comment", "b", "c#d"}},
+		{"a\\\nb \"c\\\nd\"", []string{"ab", "cd"}},
+		{"名前 '値'", []string{"名前", "値"}},
+	}
+
+	for _, test := range tests {
+		words, err := Split(test.input)
+		assertError(t, false, err)
+		assertSliceEqual(t, test.expected, words)
+	}
+
+	// comments are only recognised in response files
+	words, err := split("a #comment\nb c#d '#e'", true)
+	assertError(t, false, err)
+	assertSliceEqual(t, []string{"a", "b", "c#d", "#e"}, words)
+
+	for input, expected := range map[string]string{
+		`a 'b`:     "unterminated single quote at position 3",
+		`a "b\" c`: "unterminated double quote at position 3",
+		`名前 "x`:    "unterminated double quote at position 4",
+		`a b\`:     "trailing backslash at position 4",
+	} {
+		_, err := Split(input)
+		assertError(t, true, err)
+		if err != nil {
+			assertEqual(t, expected, err.Error())
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+	args := []string{"plain", "a b", "it's", "", "--opt=x/y.z", "$HOME", "名前"}
+	quoted := Quote(args)
+	assertEqual(t, `plain 'a b' 'it'\''s' '' --opt=x/y.z '$HOME' '名前'`, quoted)
+
+	words, err := Split(quoted)
+	assertError(t, false, err)
+	assertSliceEqual(t, args, words)
+}
+
+func TestParseString(t *testing.T) {
+	name := ""
+	parser := New()
+	parser.AddOption(String("name", &name))
+
+	assertError(t, false, parser.ParseString(`--name "a b"`))
+	assertEqual(t, "a b", name)
+
+	err := parser.ParseString(`--name "a b`)
+	assertError(t, true, err)
+	assertEqual(t, "unterminated double quote at position 8", err.Error())
+
+	// # is not a comment
+	parser = New()
+	parser.AddOption(Option{Name: "words", Positional: true, Nargs: -1})
+	inv, err := parser.ParseStringInvocation("close #12 now")
+	assertError(t, false, err)
+	assertSliceEqual(t, []string{"close", "#12", "now"}, inv.Values("words"))
+}
@@ -0,0 +1,35 @@
+# bash completion for tool
+
+_tool() {
+	local cur prev cmd i
+	COMPREPLY=()
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	cmd='tool'
+
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case "$cmd ${COMP_WORDS[i]}" in
+		'tool remote'|'tool r') cmd='tool remote' ;;
+		'tool log') cmd='tool log' ;;
+		'tool --color') ((i += 1)) ;;
+		'tool -c'|'tool --config') ((i += 1)) ;;
+		'tool remote add') cmd='tool remote add' ;;
+		'tool log --format') ((i += 1)) ;;
+		esac
+	done
+
+	case "$cmd $prev" in
+	'tool --color') COMPREPLY=($(compgen -W 'always never' -- "$cur")); return ;;
+	'tool -c'|'tool --config') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	'tool log --format') COMPREPLY=($(compgen -W 'short full' -- "$cur")); return ;;
+	esac
+
+	case "$cmd" in
+	'tool') COMPREPLY=($(compgen -W '-v --verbose --color -c --config remote log' -- "$cur")) ;;
+	'tool remote') COMPREPLY=($(compgen -W '-v --verbose add' -- "$cur")) ;;
+	'tool remote add') COMPREPLY=($(compgen -W '--fetch -v --verbose' -- "$cur")) ;;
+	'tool log') COMPREPLY=($(compgen -W '--format -v --verbose debug info' -- "$cur")) ;;
+	esac
+}
+
+complete -F _tool 'tool'
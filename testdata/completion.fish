@@ -0,0 +1,41 @@
+# fish completion for tool
+
+function __tool_command
+	set -l words (commandline -opc)
+	set -l cmd 'tool'
+	set -e words[1]
+	while set -q words[1]
+		switch "$cmd $words[1]"
+			case 'tool remote' 'tool r'
+				set cmd 'tool remote'
+			case 'tool log'
+				set cmd 'tool log'
+			case 'tool --color'
+				set -e words[1..1]
+			case 'tool -c' 'tool --config'
+				set -e words[1..1]
+			case 'tool remote add'
+				set cmd 'tool remote add'
+			case 'tool log --format'
+				set -e words[1..1]
+		end
+		set -e words[1]
+	end
+	echo $cmd
+end
+
+complete -c 'tool' -e
+complete -c 'tool' -n 'test (__tool_command) = \'tool\'' -f
+complete -c 'tool' -n 'test (__tool_command) = \'tool\'' -s 'v' -l 'verbose' -d 'verbose output'
+complete -c 'tool' -n 'test (__tool_command) = \'tool\'' -l 'color' -x -a 'always never' -d 'colorize output'
+complete -c 'tool' -n 'test (__tool_command) = \'tool\'' -s 'c' -l 'config' -r -F -d 'config file'
+complete -c 'tool' -n 'test (__tool_command) = \'tool\'' -a 'remote' -d 'manage remotes'
+complete -c 'tool' -n 'test (__tool_command) = \'tool\'' -a 'log' -d 'show logs'
+complete -c 'tool' -n 'test (__tool_command) = \'tool remote\'' -f
+complete -c 'tool' -n 'test (__tool_command) = \'tool remote\'' -s 'v' -l 'verbose' -d 'verbose output'
+complete -c 'tool' -n 'test (__tool_command) = \'tool remote\'' -a 'add' -d 'add a remote'
+complete -c 'tool' -n 'test (__tool_command) = \'tool remote add\'' -l 'fetch' -d 'fetch after adding'
+complete -c 'tool' -n 'test (__tool_command) = \'tool remote add\'' -s 'v' -l 'verbose' -d 'verbose output'
+complete -c 'tool' -n 'test (__tool_command) = \'tool log\'' -l 'format' -x -a 'short full'
+complete -c 'tool' -n 'test (__tool_command) = \'tool log\'' -s 'v' -l 'verbose' -d 'verbose output'
+complete -c 'tool' -n 'test (__tool_command) = \'tool log\'' -a 'debug info'
@@ -0,0 +1,68 @@
+# powershell completion for tool
+
+Register-ArgumentCompleter -Native -CommandName 'tool' -ScriptBlock {
+	param($wordToComplete, $commandAst, $cursorPosition)
+
+	$words = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })
+	$cmd = 'tool'
+
+	for ($i = 1; $i -lt $words.Count; $i++) {
+		switch -casesensitive ("$cmd " + $words[$i]) {
+			'tool remote' { $cmd = 'tool remote' }
+			'tool r' { $cmd = 'tool remote' }
+			'tool log' { $cmd = 'tool log' }
+			'tool --color' { $i += 1 }
+			'tool -c' { $i += 1 }
+			'tool --config' { $i += 1 }
+			'tool remote add' { $cmd = 'tool remote add' }
+			'tool log --format' { $i += 1 }
+		}
+	}
+
+	$prev = "$cmd " + $words[-1]
+	$results = switch -casesensitive ($prev) {
+		'tool --color' {
+			[System.Management.Automation.CompletionResult]::new('always', 'always', 'ParameterValue', 'always')
+			[System.Management.Automation.CompletionResult]::new('never', 'never', 'ParameterValue', 'never')
+		}
+		'tool -c' { return }
+		'tool --config' { return }
+		'tool log --format' {
+			[System.Management.Automation.CompletionResult]::new('short', 'short', 'ParameterValue', 'short')
+			[System.Management.Automation.CompletionResult]::new('full', 'full', 'ParameterValue', 'full')
+		}
+	}
+
+	if ($null -eq $results) {
+		$results = switch -casesensitive ($cmd) {
+			'tool' {
+				[System.Management.Automation.CompletionResult]::new('-v', '-v', 'ParameterName', 'verbose output')
+				[System.Management.Automation.CompletionResult]::new('--verbose', '--verbose', 'ParameterName', 'verbose output')
+				[System.Management.Automation.CompletionResult]::new('--color', '--color', 'ParameterName', 'colorize output')
+				[System.Management.Automation.CompletionResult]::new('-c', '-c', 'ParameterName', 'config file')
+				[System.Management.Automation.CompletionResult]::new('--config', '--config', 'ParameterName', 'config file')
+				[System.Management.Automation.CompletionResult]::new('remote', 'remote', 'Command', 'manage remotes')
+				[System.Management.Automation.CompletionResult]::new('log', 'log', 'Command', 'show logs')
+			}
+			'tool remote' {
+				[System.Management.Automation.CompletionResult]::new('-v', '-v', 'ParameterName', 'verbose output')
+				[System.Management.Automation.CompletionResult]::new('--verbose', '--verbose', 'ParameterName', 'verbose output')
+				[System.Management.Automation.CompletionResult]::new('add', 'add', 'Command', 'add a remote')
+			}
+			'tool remote add' {
+				[System.Management.Automation.CompletionResult]::new('--fetch', '--fetch', 'ParameterName', 'fetch after adding')
+				[System.Management.Automation.CompletionResult]::new('-v', '-v', 'ParameterName', 'verbose output')
+				[System.Management.Automation.CompletionResult]::new('--verbose', '--verbose', 'ParameterName', 'verbose output')
+			}
+			'tool log' {
+				[System.Management.Automation.CompletionResult]::new('--format', '--format', 'ParameterName', '--format')
+				[System.Management.Automation.CompletionResult]::new('-v', '-v', 'ParameterName', 'verbose output')
+				[System.Management.Automation.CompletionResult]::new('--verbose', '--verbose', 'ParameterName', 'verbose output')
+				[System.Management.Automation.CompletionResult]::new('debug', 'debug', 'ParameterValue', 'debug')
+				[System.Management.Automation.CompletionResult]::new('info', 'info', 'ParameterValue', 'info')
+			}
+		}
+	}
+
+	$results | Where-Object { $_.CompletionText -like "$wordToComplete*" }
+}
@@ -0,0 +1,93 @@
+# bash completion for kube
+
+_kube_dynamic() {
+	local out directive line ext
+	out=$("${COMP_WORDS[0]}" __complete "${COMP_WORDS[@]:1:COMP_CWORD}" 2>/dev/null) || return
+	directive=${out##*:}
+	COMPREPLY=()
+	if ((directive & 4)); then
+		local exts=()
+		while IFS= read -r line; do
+			[[ $line == :* ]] || exts+=("$line")
+		done <<< "$out"
+		if ((${#exts[@]} == 0)); then
+			COMPREPLY=($(compgen -f -- "$cur"))
+		else
+			for ext in "${exts[@]}"; do
+				COMPREPLY+=($(compgen -f -X "!*.$ext" -- "$cur"))
+			done
+			COMPREPLY+=($(compgen -d -- "$cur"))
+		fi
+	elif ((directive & 8)); then
+		COMPREPLY=($(compgen -d -- "$cur"))
+	else
+		while IFS= read -r line; do
+			[[ $line == :* ]] || COMPREPLY+=("${line%%$'\t'*}")
+		done <<< "$out"
+	fi
+	if ((directive & 1)); then
+		compopt -o nospace 2>/dev/null
+	fi
+}
+
+_kube() {
+	local cur prev cmd i
+	COMPREPLY=()
+	cur="${COMP_WORDS[COMP_CWORD]}"
+	prev="${COMP_WORDS[COMP_CWORD-1]}"
+	cmd='kube'
+
+	for ((i = 1; i < COMP_CWORD; i++)); do
+		case "$cmd ${COMP_WORDS[i]}" in
+		'kube logs') cmd='kube logs' ;;
+		'kube version') cmd='kube version' ;;
+		'kube completion') cmd='kube completion' ;;
+		'kube __complete') cmd='kube __complete' ;;
+		'kube -n'|'kube --namespace') ((i += 1)) ;;
+		'kube --context') ((i += 1)) ;;
+		'kube -f') ((i += 1)) ;;
+		'kube --dir') ((i += 1)) ;;
+		'kube logs -n'|'kube logs --namespace') ((i += 1)) ;;
+		'kube version -n'|'kube version --namespace') ((i += 1)) ;;
+		'kube completion bash') cmd='kube completion bash' ;;
+		'kube completion zsh') cmd='kube completion zsh' ;;
+		'kube completion fish') cmd='kube completion fish' ;;
+		'kube completion powershell') cmd='kube completion powershell' ;;
+		'kube completion -n'|'kube completion --namespace') ((i += 1)) ;;
+		'kube completion bash -n'|'kube completion bash --namespace') ((i += 1)) ;;
+		'kube completion zsh -n'|'kube completion zsh --namespace') ((i += 1)) ;;
+		'kube completion fish -n'|'kube completion fish --namespace') ((i += 1)) ;;
+		'kube completion powershell -n'|'kube completion powershell --namespace') ((i += 1)) ;;
+		'kube __complete -n'|'kube __complete --namespace') ((i += 1)) ;;
+		esac
+	done
+
+	case "$cmd $prev" in
+	'kube -n'|'kube --namespace') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	'kube --context') _kube_dynamic; return ;;
+	'kube -f') _kube_dynamic; return ;;
+	'kube --dir') _kube_dynamic; return ;;
+	'kube logs -n'|'kube logs --namespace') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	'kube version -n'|'kube version --namespace') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	'kube completion -n'|'kube completion --namespace') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	'kube completion bash -n'|'kube completion bash --namespace') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	'kube completion zsh -n'|'kube completion zsh --namespace') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	'kube completion fish -n'|'kube completion fish --namespace') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	'kube completion powershell -n'|'kube completion powershell --namespace') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	'kube __complete -n'|'kube __complete --namespace') COMPREPLY=($(compgen -f -- "$cur")); return ;;
+	esac
+
+	case "$cmd" in
+	'kube') COMPREPLY=($(compgen -W '-n --namespace --context -f --dir logs version completion' -- "$cur")) ;;
+	'kube logs') _kube_dynamic ;;
+	'kube version') COMPREPLY=($(compgen -W '-n --namespace' -- "$cur")) ;;
+	'kube completion') COMPREPLY=($(compgen -W '-n --namespace bash zsh fish powershell' -- "$cur")) ;;
+	'kube completion bash') COMPREPLY=($(compgen -W '-n --namespace' -- "$cur")) ;;
+	'kube completion zsh') COMPREPLY=($(compgen -W '-n --namespace' -- "$cur")) ;;
+	'kube completion fish') COMPREPLY=($(compgen -W '-n --namespace' -- "$cur")) ;;
+	'kube completion powershell') COMPREPLY=($(compgen -W '-n --namespace' -- "$cur")) ;;
+	'kube __complete') COMPREPLY=($(compgen -W '-n --namespace' -- "$cur")) ;;
+	esac
+}
+
+complete -F _kube 'kube'
@@ -0,0 +1,108 @@
+# fish completion for kube
+
+function __kube_dynamic
+	set -l args (commandline -opc)
+	set -l prog $args[1]
+	set -e args[1]
+	set -l current (commandline -ct)
+	set -l out ($prog __complete $args "$current" 2>/dev/null)
+	or return
+	set -l directive (string replace -r '^:' '' -- $out[-1])
+	set -e out[-1]
+	if test (math "bitand($directive, 4)") -ne 0
+		if set -q out[1]
+			for ext in $out
+				__fish_complete_suffix .$ext
+			end
+		else
+			__fish_complete_path "$current"
+		end
+	else if test (math "bitand($directive, 8)") -ne 0
+		__fish_complete_directories "$current"
+	else
+		printf '%s\n' $out
+	end
+end
+
+function __kube_command
+	set -l words (commandline -opc)
+	set -l cmd 'kube'
+	set -e words[1]
+	while set -q words[1]
+		switch "$cmd $words[1]"
+			case 'kube logs'
+				set cmd 'kube logs'
+			case 'kube version'
+				set cmd 'kube version'
+			case 'kube completion'
+				set cmd 'kube completion'
+			case 'kube __complete'
+				set cmd 'kube __complete'
+			case 'kube -n' 'kube --namespace'
+				set -e words[1..1]
+			case 'kube --context'
+				set -e words[1..1]
+			case 'kube -f'
+				set -e words[1..1]
+			case 'kube --dir'
+				set -e words[1..1]
+			case 'kube logs -n' 'kube logs --namespace'
+				set -e words[1..1]
+			case 'kube version -n' 'kube version --namespace'
+				set -e words[1..1]
+			case 'kube completion bash'
+				set cmd 'kube completion bash'
+			case 'kube completion zsh'
+				set cmd 'kube completion zsh'
+			case 'kube completion fish'
+				set cmd 'kube completion fish'
+			case 'kube completion powershell'
+				set cmd 'kube completion powershell'
+			case 'kube completion -n' 'kube completion --namespace'
+				set -e words[1..1]
+			case 'kube completion bash -n' 'kube completion bash --namespace'
+				set -e words[1..1]
+			case 'kube completion zsh -n' 'kube completion zsh --namespace'
+				set -e words[1..1]
+			case 'kube completion fish -n' 'kube completion fish --namespace'
+				set -e words[1..1]
+			case 'kube completion powershell -n' 'kube completion powershell --namespace'
+				set -e words[1..1]
+			case 'kube __complete -n' 'kube __complete --namespace'
+				set -e words[1..1]
+		end
+		set -e words[1]
+	end
+	echo $cmd
+end
+
+complete -c 'kube' -e
+complete -c 'kube' -n 'test (__kube_command) = \'kube\'' -f
+complete -c 'kube' -n 'test (__kube_command) = \'kube\'' -s 'n' -l 'namespace' -r -F -d 'namespace'
+complete -c 'kube' -n 'test (__kube_command) = \'kube\'' -l 'context' -x -a '(__kube_dynamic)' -d 'context to use'
+complete -c 'kube' -n 'test (__kube_command) = \'kube\'' -s 'f' -x -a '(__kube_dynamic)'
+complete -c 'kube' -n 'test (__kube_command) = \'kube\'' -l 'dir' -x -a '(__kube_dynamic)'
+complete -c 'kube' -n 'test (__kube_command) = \'kube\'' -a 'logs' -d 'print logs'
+complete -c 'kube' -n 'test (__kube_command) = \'kube\'' -a 'version'
+complete -c 'kube' -n 'test (__kube_command) = \'kube\'' -a 'completion' -d 'generates shell completion scripts'
+complete -c 'kube' -n 'test (__kube_command) = \'kube logs\'' -l 'follow'
+complete -c 'kube' -n 'test (__kube_command) = \'kube logs\'' -s 'n' -l 'namespace' -r -F -d 'namespace'
+complete -c 'kube' -n 'test (__kube_command) = \'kube logs\'' -a '(__kube_dynamic)'
+complete -c 'kube' -n 'test (__kube_command) = \'kube version\'' -f
+complete -c 'kube' -n 'test (__kube_command) = \'kube version\'' -s 'n' -l 'namespace' -r -F -d 'namespace'
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion\'' -f
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion\'' -s 'n' -l 'namespace' -r -F -d 'namespace'
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion\'' -a 'bash' -d 'generates bash completion script'
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion\'' -a 'zsh' -d 'generates zsh completion script'
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion\'' -a 'fish' -d 'generates fish completion script'
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion\'' -a 'powershell' -d 'generates powershell completion script'
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion bash\'' -f
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion bash\'' -s 'n' -l 'namespace' -r -F -d 'namespace'
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion zsh\'' -f
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion zsh\'' -s 'n' -l 'namespace' -r -F -d 'namespace'
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion fish\'' -f
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion fish\'' -s 'n' -l 'namespace' -r -F -d 'namespace'
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion powershell\'' -f
+complete -c 'kube' -n 'test (__kube_command) = \'kube completion powershell\'' -s 'n' -l 'namespace' -r -F -d 'namespace'
+complete -c 'kube' -n 'test (__kube_command) = \'kube __complete\'' -f
+complete -c 'kube' -n 'test (__kube_command) = \'kube __complete\'' -s 'n' -l 'namespace' -r -F -d 'namespace'
@@ -0,0 +1,159 @@
+# powershell completion for kube
+
+Register-ArgumentCompleter -Native -CommandName 'kube' -ScriptBlock {
+	param($wordToComplete, $commandAst, $cursorPosition)
+
+	$words = @($commandAst.CommandElements | Where-Object { $_.Extent.EndOffset -lt $cursorPosition } | ForEach-Object { $_.ToString() })
+	$cmd = 'kube'
+
+	for ($i = 1; $i -lt $words.Count; $i++) {
+		switch -casesensitive ("$cmd " + $words[$i]) {
+			'kube logs' { $cmd = 'kube logs' }
+			'kube version' { $cmd = 'kube version' }
+			'kube completion' { $cmd = 'kube completion' }
+			'kube __complete' { $cmd = 'kube __complete' }
+			'kube -n' { $i += 1 }
+			'kube --namespace' { $i += 1 }
+			'kube --context' { $i += 1 }
+			'kube -f' { $i += 1 }
+			'kube --dir' { $i += 1 }
+			'kube logs -n' { $i += 1 }
+			'kube logs --namespace' { $i += 1 }
+			'kube version -n' { $i += 1 }
+			'kube version --namespace' { $i += 1 }
+			'kube completion bash' { $cmd = 'kube completion bash' }
+			'kube completion zsh' { $cmd = 'kube completion zsh' }
+			'kube completion fish' { $cmd = 'kube completion fish' }
+			'kube completion powershell' { $cmd = 'kube completion powershell' }
+			'kube completion -n' { $i += 1 }
+			'kube completion --namespace' { $i += 1 }
+			'kube completion bash -n' { $i += 1 }
+			'kube completion bash --namespace' { $i += 1 }
+			'kube completion zsh -n' { $i += 1 }
+			'kube completion zsh --namespace' { $i += 1 }
+			'kube completion fish -n' { $i += 1 }
+			'kube completion fish --namespace' { $i += 1 }
+			'kube completion powershell -n' { $i += 1 }
+			'kube completion powershell --namespace' { $i += 1 }
+			'kube __complete -n' { $i += 1 }
+			'kube __complete --namespace' { $i += 1 }
+		}
+	}
+
+	$prev = "$cmd " + $words[-1]
+	$dynamic = switch -casesensitive ($prev) {
+		'kube --context' { $true }
+		'kube -f' { $true }
+		'kube --dir' { $true }
+	}
+	if (-not $dynamic) {
+		$dynamic = switch -casesensitive ($cmd) {
+			'kube logs' { $true }
+		}
+	}
+	if ($dynamic) {
+		$rest = @($words | Select-Object -Skip 1)
+		$out = @(& $words[0] __complete @rest $wordToComplete 2>$null)
+		if ($out.Count -eq 0) {
+			return
+		}
+		$directive = [int]$out[-1].TrimStart(':')
+		$values = @($out | Select-Object -SkipLast 1)
+		$dir = if ($wordToComplete) { Split-Path -Parent $wordToComplete } else { '' }
+		if ($directive -band 12) {
+			Get-ChildItem -Path "$wordToComplete*" | Where-Object {
+				$_.PSIsContainer -or (($directive -band 4) -and ($values.Count -eq 0 -or $values -contains $_.Extension.TrimStart('.')))
+			} | ForEach-Object {
+				$path = if ($dir) { Join-Path $dir $_.Name } else { $_.Name }
+				[System.Management.Automation.CompletionResult]::new($path, $path, 'ProviderItem', $path)
+			}
+			return
+		}
+		$values | ForEach-Object {
+			$value, $description = $_ -split "`t", 2
+			if (-not $description) {
+				$description = $value
+			}
+			[System.Management.Automation.CompletionResult]::new($value, $value, 'ParameterValue', $description)
+		}
+		return
+	}
+
+	$results = switch -casesensitive ($prev) {
+		'kube -n' { return }
+		'kube --namespace' { return }
+		'kube --context' { return }
+		'kube -f' { return }
+		'kube --dir' { return }
+		'kube logs -n' { return }
+		'kube logs --namespace' { return }
+		'kube version -n' { return }
+		'kube version --namespace' { return }
+		'kube completion -n' { return }
+		'kube completion --namespace' { return }
+		'kube completion bash -n' { return }
+		'kube completion bash --namespace' { return }
+		'kube completion zsh -n' { return }
+		'kube completion zsh --namespace' { return }
+		'kube completion fish -n' { return }
+		'kube completion fish --namespace' { return }
+		'kube completion powershell -n' { return }
+		'kube completion powershell --namespace' { return }
+		'kube __complete -n' { return }
+		'kube __complete --namespace' { return }
+	}
+
+	if ($null -eq $results) {
+		$results = switch -casesensitive ($cmd) {
+			'kube' {
+				[System.Management.Automation.CompletionResult]::new('-n', '-n', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--namespace', '--namespace', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--context', '--context', 'ParameterName', 'context to use')
+				[System.Management.Automation.CompletionResult]::new('-f', '-f', 'ParameterName', '-f')
+				[System.Management.Automation.CompletionResult]::new('--dir', '--dir', 'ParameterName', '--dir')
+				[System.Management.Automation.CompletionResult]::new('logs', 'logs', 'Command', 'print logs')
+				[System.Management.Automation.CompletionResult]::new('version', 'version', 'Command', 'version')
+				[System.Management.Automation.CompletionResult]::new('completion', 'completion', 'Command', 'generates shell completion scripts')
+			}
+			'kube logs' {
+				[System.Management.Automation.CompletionResult]::new('--follow', '--follow', 'ParameterName', '--follow')
+				[System.Management.Automation.CompletionResult]::new('-n', '-n', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--namespace', '--namespace', 'ParameterName', 'namespace')
+			}
+			'kube version' {
+				[System.Management.Automation.CompletionResult]::new('-n', '-n', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--namespace', '--namespace', 'ParameterName', 'namespace')
+			}
+			'kube completion' {
+				[System.Management.Automation.CompletionResult]::new('-n', '-n', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--namespace', '--namespace', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('bash', 'bash', 'Command', 'generates bash completion script')
+				[System.Management.Automation.CompletionResult]::new('zsh', 'zsh', 'Command', 'generates zsh completion script')
+				[System.Management.Automation.CompletionResult]::new('fish', 'fish', 'Command', 'generates fish completion script')
+				[System.Management.Automation.CompletionResult]::new('powershell', 'powershell', 'Command', 'generates powershell completion script')
+			}
+			'kube completion bash' {
+				[System.Management.Automation.CompletionResult]::new('-n', '-n', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--namespace', '--namespace', 'ParameterName', 'namespace')
+			}
+			'kube completion zsh' {
+				[System.Management.Automation.CompletionResult]::new('-n', '-n', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--namespace', '--namespace', 'ParameterName', 'namespace')
+			}
+			'kube completion fish' {
+				[System.Management.Automation.CompletionResult]::new('-n', '-n', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--namespace', '--namespace', 'ParameterName', 'namespace')
+			}
+			'kube completion powershell' {
+				[System.Management.Automation.CompletionResult]::new('-n', '-n', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--namespace', '--namespace', 'ParameterName', 'namespace')
+			}
+			'kube __complete' {
+				[System.Management.Automation.CompletionResult]::new('-n', '-n', 'ParameterName', 'namespace')
+				[System.Management.Automation.CompletionResult]::new('--namespace', '--namespace', 'ParameterName', 'namespace')
+			}
+		}
+	}
+
+	$results | Where-Object { $_.CompletionText -like "$wordToComplete*" }
+}
@@ -64,10 +64,11 @@ func Sscanf(name string, format string, v ...any) Option {
 	return Option{Name: name, Nargs: 1, Callback: func(ctx *Context, args ...string) {
 		if _, err := fmt.Sscanf(args[0], format, v...); err != nil {
 			var rerr error
-			if errors.Is(err, io.EOF) {
-				rerr = fmt.Errorf("option %s %q is invalid", ctx.Option().String(), args[0])
+			// the scan error may quote the value
+			if errors.Is(err, io.EOF) || ctx.Option().Secret {
+				rerr = fmt.Errorf("option %s is invalid", optionValue(ctx.Option(), args[0]))
 			} else {
-				rerr = fmt.Errorf("option %s %q is invalid: %s", ctx.Option().String(), args[0], err.Error())
+				rerr = fmt.Errorf("option %s is invalid: %s", optionValue(ctx.Option(), args[0]), err.Error())
 			}
 			ctx.AbortWithError(rerr)
 		}
@@ -77,7 +78,7 @@ func Sscanf(name string, format string, v ...any) Option {
 func Int(name string, v *int) Option {
 	return Option{Name: name, Nargs: 1, Callback: func(ctx *Context, args ...string) {
 		if num, err := strconv.Atoi(args[0]); err != nil {
-			ctx.AbortWithError(fmt.Errorf("option %s %q requires an integer", ctx.Option().String(), args[0]))
+			ctx.AbortWithError(fmt.Errorf("option %s requires an integer", optionValue(ctx.Option(), args[0])))
 		} else {
 			*v = num
 		}
@@ -87,7 +88,7 @@ func Int(name string, v *int) Option {
 func Uint(name string, v *uint) Option {
 	return Option{Name: name, Nargs: 1, Callback: func(ctx *Context, args ...string) {
 		if num, err := strconv.ParseUint(args[0], 10, 0); err != nil {
-			ctx.AbortWithError(fmt.Errorf("option %s %q requires an unsigned integer", ctx.Option().String(), args[0]))
+			ctx.AbortWithError(fmt.Errorf("option %s requires an unsigned integer", optionValue(ctx.Option(), args[0])))
 		} else {
 			*v = uint(num)
 		}
@@ -101,7 +102,7 @@ func IntPositional(name string, v *int) Option {
 func IntAppendPositional(name string, v *[]int) Option {
 	return Option{Name: name, Positional: true, Nargs: -1, Callback: func(ctx *Context, args ...string) {
 		if num, err := strconv.Atoi(args[0]); err != nil {
-			ctx.AbortWithError(fmt.Errorf("option %s %q requires an integer", ctx.Option().String(), args[0]))
+			ctx.AbortWithError(fmt.Errorf("option %s requires an integer", optionValue(ctx.Option(), args[0])))
 		} else {
 			*v = append(*v, num)
 		}
@@ -0,0 +1,153 @@
+package argparse
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime/debug"
+	"strings"
+)
+
+// returned by Parse when the version is requested and Exit returns
+var ErrVersion = errors.New("version requested")
+
+// details of the running binary shown by --version --verbose
+type BuildInfo struct {
+	Module   string
+	Version  string
+	Revision string
+	// time of the commit, not of the build
+	Time      string
+	Modified  bool
+	GoVersion string
+}
+
+// return the build information embedded in the running binary
+func ReadBuildInfo() (BuildInfo, bool) {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return BuildInfo{}, false
+	}
+	b := BuildInfo{
+		Module:    info.Main.Path,
+		Version:   info.Main.Version,
+		GoVersion: info.GoVersion,
+	}
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			b.Revision = setting.Value
+		case "vcs.time":
+			b.Time = setting.Value
+		case "vcs.modified":
+			b.Modified = setting.Value == "true"
+		}
+	}
+	return b, true
+}
+
+// sets BuildInfo, and Version if empty, from the running binary
+func (a *ArgParser) SetBuildInfo() {
+	info, ok := ReadBuildInfo()
+	if !ok {
+		return
+	}
+	a.BuildInfo = &info
+	if len(a.Version) == 0 {
+		a.Version = info.Version
+	}
+}
+
+// return the parser defining the version of a
+func (a *ArgParser) versionParser() *ArgParser {
+	for p := a; p != nil; p = p.parent {
+		if len(p.Version) > 0 || p.BuildInfo != nil {
+			return p
+		}
+	}
+	return a
+}
+
+// writes the program name followed by its version. if verbose, the build
+// information is written after it
+func (a *ArgParser) WriteVersion(w io.Writer, verbose bool) error {
+	p := a.versionParser()
+	version := p.Version
+	if len(version) == 0 && p.BuildInfo != nil {
+		version = p.BuildInfo.Version
+	}
+
+	b := &strings.Builder{}
+	b.WriteString(p.commandPath()[0])
+	if len(version) > 0 {
+		b.WriteString(" " + version)
+	}
+	b.WriteRune('\n')
+
+	if info := p.BuildInfo; verbose && info != nil {
+		if len(info.Module) > 0 {
+			fmt.Fprintf(b, "module: %s\n", info.Module)
+		}
+		if len(info.Revision) > 0 {
+			revision := info.Revision
+			if info.Modified {
+				revision += " (modified)"
+			}
+			fmt.Fprintf(b, "revision: %s\n", revision)
+		}
+		if len(info.Time) > 0 {
+			fmt.Fprintf(b, "commit time: %s\n", info.Time)
+		}
+		if len(info.GoVersion) > 0 {
+			fmt.Fprintf(b, "go: %s\n", info.GoVersion)
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// adds -V, --version showing the version and exiting. it is verbose if the
+// option named verbose, added by the caller, is given before or after it.
+// an empty verbose disables the verbose output
+func (a *ArgParser) AddVersionOption(verbose string) {
+	a.AddOptionWithAlias(Option{Name: "V", Description: "shows version and exits", Callback: func(ctx *Context, args ...string) {
+		ctx.parser.WriteVersion(ctx.inv.Stdout(), ctx.verbose(verbose))
+		ctx.inv.doExit(0)
+		ctx.AbortWithError(ErrVersion)
+	}}, "version")
+}
+
+// return whether the option name is set or among the remaining arguments,
+// by any of its names
+func (c *Context) verbose(name string) bool {
+	if len(name) == 0 {
+		return false
+	}
+	if c.inv.IsSet(name) {
+		return true
+	}
+	verbose, owner := c.parser.findOption(name)
+	if verbose == nil {
+		return false
+	}
+	verbose = owner.base(verbose)
+	for _, arg := range c.args {
+		if arg == "--" {
+			break
+		}
+		for _, val := range c.expand(arg) {
+			if !strings.HasPrefix(val, "-") {
+				continue
+			}
+			opt, err := c.getOption(val)
+			if err != nil || opt == nil {
+				continue
+			}
+			if opt, owner := c.parser.findOption(opt.Name); opt != nil && owner.base(opt) == verbose {
+				return true
+			}
+		}
+	}
+	return false
+}
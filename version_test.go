@@ -0,0 +1,71 @@
+package argparse
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVersion(t *testing.T) {
+	stdout := &strings.Builder{}
+	verbose := false
+
+	parser := New()
+	parser.Name = "./tool"
+	parser.Version = "1.2.0"
+	parser.BuildInfo = &BuildInfo{Module: "example.com/tool", Revision: "abc123", Modified: true, Time: "2024-01-01T00:00:00Z", GoVersion: "go1.22.0"}
+	parser.Stdout = stdout
+	parser.Exit = func(code int) {}
+	parser.AddOptionWithAlias(Bool("verbose", &verbose), "v")
+	parser.AddVersionOption("verbose")
+
+	err := parser.Parse("--version")
+	if err != ErrVersion {
+		t.Errorf("expected ErrVersion; got %v", err)
+	}
+	assertEqual(t, "tool 1.2.0\n", stdout.String())
+
+	for _, args := range [][]string{{"-V", "--verbose"}, {"-V", "-v"}, {"-Vv"}, {"-v", "-V"}} {
+		stdout.Reset()
+		parser.Parse(args...)
+		assertEqual(t, "tool 1.2.0\n"+
+			"module: example.com/tool\n"+
+			"revision: abc123 (modified)\n"+
+			"commit time: 2024-01-01T00:00:00Z\n"+
+			"go: go1.22.0\n", stdout.String())
+	}
+
+	// arguments after -- are not options
+	stdout.Reset()
+	parser.Parse("-V", "--", "-v")
+	assertEqual(t, "tool 1.2.0\n", stdout.String())
+
+	// subparsers show the version of their parent
+	sub := New()
+	sub.AddVersionOption("")
+	parser.AddSubParser("sub", sub)
+	stdout.Reset()
+	parser.Parse("sub", "-V")
+	assertEqual(t, "tool 1.2.0\n", stdout.String())
+
+	// no option is added besides -V, --version
+	other := New()
+	other.Name = "tool"
+	other.Version = "1.2.0"
+	other.BuildInfo = parser.BuildInfo
+	other.Stdout = stdout
+	other.Exit = func(code int) {}
+	other.AddVersionOption("")
+	assertError(t, true, other.Parse("--verbose", "-V"))
+	stdout.Reset()
+	if err := other.Parse("-V"); err != ErrVersion {
+		t.Errorf("expected ErrVersion; got %v", err)
+	}
+	assertEqual(t, "tool 1.2.0\n", stdout.String())
+
+	parser.Version = ""
+	parser.BuildInfo = nil
+	parser.SetBuildInfo()
+	if parser.BuildInfo == nil || len(parser.Version) == 0 {
+		t.Errorf("expected build info to be set")
+	}
+}
@@ -0,0 +1,146 @@
+package argparse
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// east asian wide and fullwidth ranges, taking two columns
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f}, {0x231a, 0x231b}, {0x2329, 0x232a}, {0x23e9, 0x23ec},
+	{0x23f0, 0x23f0}, {0x23f3, 0x23f3}, {0x25fd, 0x25fe}, {0x2614, 0x2615},
+	{0x2648, 0x2653}, {0x267f, 0x267f}, {0x2693, 0x2693}, {0x26a1, 0x26a1},
+	{0x26aa, 0x26ab}, {0x26bd, 0x26be}, {0x26c4, 0x26c5}, {0x26ce, 0x26ce},
+	{0x26d4, 0x26d4}, {0x26ea, 0x26ea}, {0x26f2, 0x26f3}, {0x26f5, 0x26f5},
+	{0x26fa, 0x26fa}, {0x26fd, 0x26fd}, {0x2705, 0x2705}, {0x270a, 0x270b},
+	{0x2728, 0x2728}, {0x274c, 0x274c}, {0x274e, 0x274e}, {0x2753, 0x2755},
+	{0x2757, 0x2757}, {0x2795, 0x2797}, {0x27b0, 0x27b0}, {0x27bf, 0x27bf},
+	{0x2b1b, 0x2b1c}, {0x2b50, 0x2b50}, {0x2b55, 0x2b55}, {0x2e80, 0x303e},
+	{0x3041, 0x33ff}, {0x3400, 0x4dbf}, {0x4e00, 0x9fff}, {0xa000, 0xa4cf},
+	{0xa960, 0xa97f}, {0xac00, 0xd7a3}, {0xf900, 0xfaff}, {0xfe10, 0xfe19},
+	{0xfe30, 0xfe6f}, {0xff00, 0xff60}, {0xffe0, 0xffe6}, {0x1f300, 0x1f64f},
+	{0x1f900, 0x1f9ff}, {0x20000, 0x2fffd}, {0x30000, 0x3fffd},
+}
+
+// return the number of terminal columns r takes
+func runeWidth(r rune) int {
+	if r == 0 || unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf) {
+		return 0
+	}
+	for _, wide := range wideRanges {
+		if r < wide[0] {
+			break
+		}
+		if r <= wide[1] {
+			return 2
+		}
+	}
+	return 1
+}
+
+// return the number of terminal columns s takes, ignoring ansi escape
+// sequences
+func displayWidth(s string) int {
+	w := 0
+	escape := false
+	for i, r := range s {
+		switch {
+		case escape:
+			// sequences end with a byte in the range @ to ~
+			if r >= '@' && r <= '~' && s[i-1] != '\x1b' {
+				escape = false
+			}
+		case r == '\x1b':
+			escape = true
+		default:
+			w += runeWidth(r)
+		}
+	}
+	return w
+}
+
+// return the wrapping width of a: its Width, the Width of the closest parent
+// that has one, $COLUMNS or BreakLineThreshold
+func (a *ArgParser) width() int {
+	for p := a; p != nil; p = p.parent {
+		if p.Width > 0 {
+			return p.Width
+		}
+	}
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	return BreakLineThreshold
+}
+
+// return whether line starts a list item, and its marker
+func bullet(line string) (string, bool) {
+	line = strings.TrimLeftFunc(line, unicode.IsSpace)
+	for _, marker := range []string{"-", "*", "•"} {
+		if strings.HasPrefix(line, marker+" ") {
+			return marker, true
+		}
+	}
+	i := 0
+	for i < len(line) && line[i] >= '0' && line[i] <= '9' {
+		i++
+	}
+	if i > 0 && i+1 < len(line) && (line[i] == '.' || line[i] == ')') && line[i+1] == ' ' {
+		return line[:i+1], true
+	}
+	return "", false
+}
+
+// return text wrapped to width after linestart. paragraphs (separated by
+// blank lines) are kept apart and list items start on their own line, with
+// continuation lines aligned to the item text. lines after the first are
+// padded to padlen
+func wrapParagraphs(linestart string, padlen, width int, text string) string {
+	b := &strings.Builder{}
+	pad := strings.Repeat(" ", padlen)
+	first := true
+
+	write := func(marker string, words []string) {
+		if len(words) == 0 {
+			return
+		}
+		start := pad
+		if first {
+			start = linestart
+		}
+		if len(marker) > 0 {
+			start += marker + " "
+		}
+		b.WriteString(formatString(start, padlen+displayWidth(start)-displayWidth(pad), width, false, words...))
+		first = false
+	}
+
+	for _, paragraph := range strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n\n") {
+		if len(strings.TrimSpace(paragraph)) == 0 {
+			continue
+		}
+		if !first {
+			b.WriteRune('\n')
+		}
+
+		marker := ""
+		words := make([]string, 0)
+		for _, line := range strings.Split(paragraph, "\n") {
+			if m, ok := bullet(line); ok {
+				write(marker, words)
+				marker = m
+				line = strings.TrimPrefix(strings.TrimLeftFunc(line, unicode.IsSpace), m)
+				words = words[:0]
+			}
+			words = append(words, strings.FieldsFunc(line, unicode.IsSpace)...)
+		}
+		write(marker, words)
+	}
+
+	if first {
+		return strings.TrimSuffix(linestart, " ") + "\n"
+	}
+	return b.String()
+}